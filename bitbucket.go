@@ -0,0 +1,214 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// bitbucketAPI is the API root of Bitbucket Cloud
+const bitbucketAPI = "https://api.bitbucket.org/2.0"
+
+// Bitbucket is a Forge for Bitbucket Cloud. Owners are workspaces.
+// Bitbucket pull requests don't have labels, so the PR label check never matches.
+type Bitbucket struct {
+	api *restClient
+}
+
+// NewBitbucket returns a Bitbucket for https://bitbucket.org.
+// baseURL may instead be the root of a Bitbucket Cloud compatible API.
+// token is an access token and may be empty to check public repositories.
+// If httpClient is nil, http.DefaultClient is used.
+func NewBitbucket(httpClient *http.Client, baseURL string, token string) (*Bitbucket, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitbucket url %q: %v", baseURL, err)
+	}
+	if baseURL == "" || ForgeKind(u.Host) == ForgeBitbucket {
+		baseURL = bitbucketAPI
+	}
+	api, err := newRESTClient(httpClient, baseURL, func(req *http.Request) {
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitbucket url: %v", err)
+	}
+	return &Bitbucket{api: api}, nil
+}
+
+func (b *Bitbucket) DefaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	var r struct {
+		MainBranch struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	if _, err := b.api.get(ctx, b.repo(owner, repo), nil, &r); err != nil {
+		return "", fmt.Errorf("%s/%s: %w", owner, repo, err)
+	}
+	return r.MainBranch.Name, nil
+}
+
+func (b *Bitbucket) Source(ctx context.Context, owner, repo, branch string) (Source, error) {
+	// the src API takes a commit, and branch names with slashes are ambiguous in its paths
+	var ref struct {
+		Target struct {
+			Hash string `json:"hash"`
+		} `json:"target"`
+	}
+	if _, err := b.api.get(ctx, b.repo(owner, repo)+"/refs/branches/"+url.PathEscape(branch), nil, &ref); err != nil {
+		return nil, fmt.Errorf("failed to get %s/%s branch %s: %v", owner, repo, branch, err)
+	}
+	return &bitbucketSource{
+		bitbucket: b,
+		owner:     owner,
+		repo:      repo,
+		commit:    ref.Target.Hash,
+		dirs:      make(map[string][]*Entry),
+	}, nil
+}
+
+func (b *Bitbucket) PullRequests(ctx context.Context, owner, repo string) ([]PullRequest, error) {
+	q := url.Values{
+		"state":   {"OPEN", "MERGED", "DECLINED", "SUPERSEDED"},
+		"pagelen": {"50"},
+	}
+	var page struct {
+		Values []struct {
+			ID int `json:"id"`
+		} `json:"values"`
+	}
+	if _, err := b.api.get(ctx, b.repo(owner, repo)+"/pullrequests", q, &page); err != nil {
+		return nil, err
+	}
+	prs := make([]PullRequest, 0, len(page.Values))
+	for _, pr := range page.Values {
+		prs = append(prs, PullRequest{Number: pr.ID})
+	}
+	return prs, nil
+}
+
+func (b *Bitbucket) Commits(ctx context.Context, owner, repo, branch string, n int) ([]Commit, error) {
+	q := url.Values{"pagelen": {strconv.Itoa(n)}}
+	var page struct {
+		Values []struct {
+			Hash    string `json:"hash"`
+			Message string `json:"message"`
+		} `json:"values"`
+	}
+	if _, err := b.api.get(ctx, b.repo(owner, repo)+"/commits/"+url.PathEscape(branch), q, &page); err != nil {
+		return nil, err
+	}
+	commits := make([]Commit, 0, len(page.Values))
+	for _, c := range page.Values {
+		commits = append(commits, Commit{SHA: c.Hash, Message: c.Message})
+	}
+	return commits, nil
+}
+
+func (b *Bitbucket) repo(owner, repo string) string {
+	return "repositories/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+}
+
+// bitbucketSource lists directories as they're needed, since Bitbucket has no recursive tree API
+type bitbucketSource struct {
+	bitbucket *Bitbucket
+	owner     string
+	repo      string
+	commit    string
+
+	mu   sync.Mutex
+	dirs map[string][]*Entry
+}
+
+func (b *bitbucketSource) revision() string {
+	return b.commit
+}
+
+func (b *bitbucketSource) Find(ctx context.Context, path string) (*Entry, error) {
+	dir, _ := splitPath(path)
+	entries, err := b.List(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Path == path {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *bitbucketSource) List(ctx context.Context, dir string) ([]*Entry, error) {
+	b.mu.Lock()
+	entries, ok := b.dirs[dir]
+	b.mu.Unlock()
+	if ok {
+		return entries, nil
+	}
+
+	u, err := b.bitbucket.api.baseURL.Parse(b.src(dir) + "/")
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{"pagelen": {"100"}}
+	for u != nil {
+		var page struct {
+			Values []struct {
+				Path string `json:"path"`
+				Type string `json:"type"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		_, err := b.bitbucket.api.getURL(ctx, u, q, &page)
+		if errors.Is(err, ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s/%s/%s: %v", b.owner, b.repo, dir, err)
+		}
+		for _, v := range page.Values {
+			entries = append(entries, &Entry{Path: v.Path, Dir: v.Type == "commit_directory"})
+		}
+		u, q = nil, nil
+		if page.Next != "" {
+			if u, err = url.Parse(page.Next); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	b.mu.Lock()
+	b.dirs[dir] = entries
+	b.mu.Unlock()
+	return entries, nil
+}
+
+func (b *bitbucketSource) ReadFile(ctx context.Context, e *Entry) ([]byte, error) {
+	var content []byte
+	if _, err := b.bitbucket.api.get(ctx, b.src(e.Path), nil, &content); err != nil {
+		return nil, fmt.Errorf("error getting %s: %v", e.Path, err)
+	}
+	return content, nil
+}
+
+// src returns the API path of a file or directory at the source's commit
+func (b *bitbucketSource) src(path string) string {
+	p := b.bitbucket.repo(b.owner, b.repo) + "/src/" + b.commit
+	if path == "" {
+		return p
+	}
+	return p + "/" + escapePath(path)
+}
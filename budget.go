@@ -0,0 +1,366 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v43/github"
+)
+
+// ErrRateLimitBudget is matched by errors caused by the GitHub rate limit being too low to run checks
+var ErrRateLimitBudget = errors.New("remaining github rate limit too low")
+
+// snapshotCost is the cost of looking up the default branch, its head commit, and fetching its tree
+var snapshotCost = Cost{Core: 3}
+
+// workflowEstimate is the assumed number of files in a directory, like .github/workflows, when a truncated tree hides the real count
+const workflowEstimate = 20
+
+// Cost is a number of GitHub API requests, split by rate limit category
+type Cost struct {
+	Core    int
+	Search  int
+	GraphQL int
+}
+
+func (c Cost) add(o Cost) Cost {
+	return Cost{
+		Core:    c.Core + o.Core,
+		Search:  c.Search + o.Search,
+		GraphQL: c.GraphQL + o.GraphQL,
+	}
+}
+
+func (c Cost) sub(o Cost) Cost {
+	return Cost{
+		Core:    c.Core - o.Core,
+		Search:  c.Search - o.Search,
+		GraphQL: c.GraphQL - o.GraphQL,
+	}
+}
+
+// fits reports whether c can be paid for out of remaining
+func (c Cost) fits(remaining Cost) bool {
+	return c.Core <= remaining.Core && c.Search <= remaining.Search && c.GraphQL <= remaining.GraphQL
+}
+
+// exceeds reports whether c uses any category where needed doesn't fit in remaining
+func (c Cost) exceeds(needed, remaining Cost) bool {
+	return (c.Core > 0 && needed.Core > remaining.Core) ||
+		(c.Search > 0 && needed.Search > remaining.Search) ||
+		(c.GraphQL > 0 && needed.GraphQL > remaining.GraphQL)
+}
+
+func (c Cost) total() int {
+	return c.Core + c.Search + c.GraphQL
+}
+
+func (c Cost) String() string {
+	return fmt.Sprintf("%d core, %d search, %d graphql", c.Core, c.Search, c.GraphQL)
+}
+
+// Coster is implemented by detectors that can estimate the worst-case
+// number of API requests they'll make against a snapshot.
+// Detectors that don't implement it are assumed to make one core request.
+type Coster interface {
+	Cost(s *Snapshot) Cost
+}
+
+// BudgetPolicy decides what happens when the remaining rate limit can't cover every check
+type BudgetPolicy int
+
+const (
+	// BudgetFail returns a *BudgetError without running any checks
+	BudgetFail BudgetPolicy = iota
+	// BudgetWait sleeps until the rate limit resets, then runs every check
+	BudgetWait
+	// BudgetDegrade skips the most expensive checks until the rest fit
+	BudgetDegrade
+)
+
+// ParseBudgetPolicy parses "fail", "wait" or "degrade" into a BudgetPolicy
+func ParseBudgetPolicy(s string) (BudgetPolicy, error) {
+	switch s {
+	case "fail":
+		return BudgetFail, nil
+	case "wait":
+		return BudgetWait, nil
+	case "degrade":
+		return BudgetDegrade, nil
+	}
+	return BudgetFail, fmt.Errorf("unknown budget policy %q, expected fail, wait or degrade", s)
+}
+
+// BudgetError reports the checks that were skipped because of the rate limit
+type BudgetError struct {
+	// Needed is the estimated cost of every check
+	Needed Cost
+	// Remaining is what was left of the rate limit
+	Remaining Cost
+	// Reset is when the exhausted rate limit resets
+	Reset time.Time
+	// Skipped is the names of the detectors that didn't run
+	Skipped []string
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("%v: needed %v, had %v until %s, skipped %s",
+		ErrRateLimitBudget, e.Needed, e.Remaining, e.Reset.Format(time.RFC3339), strings.Join(e.Skipped, ", "))
+}
+
+func (e *BudgetError) Is(target error) bool {
+	return target == ErrRateLimitBudget
+}
+
+type rateLimits struct {
+	Resources struct {
+		Core    *github.Rate `json:"core"`
+		Search  *github.Rate `json:"search"`
+		GraphQL *github.Rate `json:"graphql"`
+	} `json:"resources"`
+}
+
+func (l rateLimits) remaining() Cost {
+	var c Cost
+	if l.Resources.Core != nil {
+		c.Core = l.Resources.Core.Remaining
+	}
+	if l.Resources.Search != nil {
+		c.Search = l.Resources.Search.Remaining
+	}
+	if l.Resources.GraphQL != nil {
+		c.GraphQL = l.Resources.GraphQL.Remaining
+	}
+	return c
+}
+
+// reset returns the latest reset time of the categories needed doesn't fit in
+func (l rateLimits) reset(needed Cost) time.Time {
+	var t time.Time
+	remaining := l.remaining()
+	for _, r := range []struct {
+		rate   *github.Rate
+		needed int
+		left   int
+	}{
+		{l.Resources.Core, needed.Core, remaining.Core},
+		{l.Resources.Search, needed.Search, remaining.Search},
+		{l.Resources.GraphQL, needed.GraphQL, remaining.GraphQL},
+	} {
+		if r.rate != nil && r.needed > r.left && r.rate.Reset.After(t) {
+			t = r.rate.Reset.Time
+		}
+	}
+	return t
+}
+
+// less returns l with c taken from the remaining requests of each category
+func (l rateLimits) less(c Cost) *rateLimits {
+	sub := func(r *github.Rate, n int) *github.Rate {
+		if r == nil {
+			return nil
+		}
+		rate := *r
+		rate.Remaining -= n
+		return &rate
+	}
+	out := new(rateLimits)
+	out.Resources.Core = sub(l.Resources.Core, c.Core)
+	out.Resources.Search = sub(l.Resources.Search, c.Search)
+	out.Resources.GraphQL = sub(l.Resources.GraphQL, c.GraphQL)
+	return out
+}
+
+// expired reports whether any category has reset since l was fetched
+func (l rateLimits) expired(now time.Time) bool {
+	for _, r := range []*github.Rate{l.Resources.Core, l.Resources.Search, l.Resources.GraphQL} {
+		if r != nil && !r.Reset.IsZero() && now.After(r.Reset.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// RateBudget shares one GitHub rate limit between checks running at the same time, like the repositories of ScanOrg.
+// Each check reserves its estimated cost before making requests,
+// so concurrent checks don't each plan against the whole limit.
+// The zero value is ready to use.
+type RateBudget struct {
+	mu       sync.Mutex
+	limits   *rateLimits
+	reserved Cost
+}
+
+// reserve calls take with the remaining budget and reserves the cost it returns.
+// It returns the limits take saw, fetching them first if they haven't been or have since reset.
+func (b *RateBudget) reserve(ctx context.Context, client *github.Client, take func(remaining Cost) Cost) (*rateLimits, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limits == nil || b.limits.expired(time.Now()) {
+		limits, err := getRateLimits(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get github rate limit: %w", err)
+		}
+		b.limits, b.reserved = limits, Cost{}
+	}
+	limits := b.limits.less(b.reserved)
+	b.reserved = b.reserved.add(take(limits.remaining()))
+	return limits, nil
+}
+
+// getRateLimits is client.RateLimits, but includes the GraphQL limit missing from go-github
+func getRateLimits(ctx context.Context, client *github.Client) (*rateLimits, error) {
+	req, err := client.NewRequest("GET", "rate_limit", nil)
+	if err != nil {
+		return nil, err
+	}
+	limits := new(rateLimits)
+	if _, err := client.Do(ctx, req, limits); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// waitUntil blocks until t or ctx is done
+func waitUntil(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cost returns the estimated cost of running d against s
+func cost(d Detector, s *Snapshot) Cost {
+	if c, ok := d.(Coster); ok {
+		return c.Cost(s)
+	}
+	return Cost{Core: 1}
+}
+
+// planCost returns the estimated cost of running every detector against s
+func planCost(detectors []Detector, s *Snapshot) Cost {
+	var c Cost
+	for _, d := range detectors {
+		c = c.add(cost(d, s))
+	}
+	return c
+}
+
+func detectorNames(detectors []Detector) []string {
+	names := make([]string, 0, len(detectors))
+	for _, d := range detectors {
+		names = append(names, d.Name())
+	}
+	return names
+}
+
+// plan splits detectors into those that fit in remaining and those that don't,
+// dropping the most expensive first
+func plan(detectors []Detector, s *Snapshot, remaining Cost) (run []Detector, skipped []Detector) {
+	costs := make(map[string]Cost, len(detectors))
+	var needed Cost
+	for _, d := range detectors {
+		costs[d.Name()] = cost(d, s)
+		needed = needed.add(costs[d.Name()])
+	}
+
+	byCost := make([]Detector, len(detectors))
+	copy(byCost, detectors)
+	sort.SliceStable(byCost, func(i, j int) bool {
+		return costs[byCost[i].Name()].total() > costs[byCost[j].Name()].total()
+	})
+	drop := make(map[string]bool)
+	for _, d := range byCost {
+		if needed.fits(remaining) {
+			break
+		}
+		if !costs[d.Name()].exceeds(needed, remaining) {
+			continue
+		}
+		drop[d.Name()] = true
+		needed = needed.sub(costs[d.Name()])
+	}
+
+	for _, d := range detectors {
+		if drop[d.Name()] {
+			skipped = append(skipped, d)
+		} else {
+			run = append(run, d)
+		}
+	}
+	return run, skipped
+}
+
+// sourceCoster is implemented by sources that make API requests
+type sourceCoster interface {
+	// findCost is the worst-case cost of finding path
+	findCost(path string) Cost
+	// listCost is the worst-case cost of listing dir
+	listCost(dir string) Cost
+	// readCost is the cost of reading one file
+	readCost() Cost
+	// count returns the number of entries in dir, if it's known without a request
+	count(dir string) (int, bool)
+}
+
+// findCost is the worst-case cost of s.find(path)
+func (s *Snapshot) findCost(path string) Cost {
+	if c, ok := s.src.(sourceCoster); ok {
+		return c.findCost(path)
+	}
+	return Cost{}
+}
+
+// listCost is the worst-case cost of s.list(dir)
+func (s *Snapshot) listCost(dir string) Cost {
+	if c, ok := s.src.(sourceCoster); ok {
+		return c.listCost(dir)
+	}
+	return Cost{}
+}
+
+// contentCost is the worst-case cost of s.contentAtPath(path)
+func (s *Snapshot) contentCost(path string) Cost {
+	if c, ok := s.src.(sourceCoster); ok {
+		return c.findCost(path).add(c.readCost())
+	}
+	return Cost{}
+}
+
+// workflowsCost is the worst-case cost of reading every workflow file
+func (s *Snapshot) workflowsCost() Cost {
+	return s.filesCost(".github/workflows")
+}
+
+// filesCost is the worst-case cost of listing dir and reading every file in it
+func (s *Snapshot) filesCost(dir string) Cost {
+	c, ok := s.src.(sourceCoster)
+	if !ok {
+		return Cost{}
+	}
+	n, known := c.count(dir)
+	if !known {
+		n = workflowEstimate
+	}
+	cost := c.listCost(dir)
+	for i := 0; i < n; i++ {
+		cost = cost.add(c.readCost())
+	}
+	return cost
+}
@@ -0,0 +1,183 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v43/github"
+)
+
+func costDetector(name string, c Cost) Detector {
+	return detector{
+		name: name,
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return false, nil
+		},
+		cost: func(s *Snapshot) Cost {
+			return c
+		},
+	}
+}
+
+func TestPlan(t *testing.T) {
+	s := &Snapshot{src: &githubSource{tree: &github.Tree{}}}
+	detectors := []Detector{
+		costDetector("free", Cost{}),
+		costDetector("cheap", Cost{Core: 1}),
+		costDetector("expensive", Cost{Core: 10}),
+		costDetector("search", Cost{Search: 1}),
+	}
+	tests := []struct {
+		remaining Cost
+		skipped   []string
+	}{
+		{Cost{Core: 100, Search: 10}, nil},
+		{Cost{Core: 5, Search: 10}, []string{"expensive"}},
+		{Cost{Core: 100}, []string{"search"}},
+		{Cost{}, []string{"cheap", "expensive", "search"}},
+	}
+	for _, tt := range tests {
+		run, skipped := plan(detectors, s, tt.remaining)
+		if len(run)+len(skipped) != len(detectors) {
+			t.Errorf("%v: plan lost detectors, ran %d and skipped %d", tt.remaining, len(run), len(skipped))
+		}
+		var names []string
+		if skipped != nil {
+			names = detectorNames(skipped)
+		}
+		if !reflect.DeepEqual(names, tt.skipped) {
+			t.Errorf("%v: skipped %v, wanted %v", tt.remaining, names, tt.skipped)
+		}
+	}
+}
+
+func TestBudgetErrorIs(t *testing.T) {
+	var err error = &BudgetError{Skipped: []string{"pr-label"}}
+	if !errors.Is(err, ErrRateLimitBudget) {
+		t.Errorf("expected *BudgetError to match ErrRateLimitBudget")
+	}
+}
+
+func TestWorkflowsCost(t *testing.T) {
+	src := &githubSource{tree: &github.Tree{Entries: []*github.TreeEntry{
+		{Path: github.String(".github/workflows/a.yml")},
+		{Path: github.String(".github/workflows/b.yml")},
+		{Path: github.String(".github/dco.yml")},
+	}}}
+	s := &Snapshot{src: src}
+	if c := s.workflowsCost(); c.Core != 2 {
+		t.Errorf("got %v for two workflows", c)
+	}
+	src.tree.Truncated = github.Bool(true)
+	if c := s.workflowsCost(); c.Core != workflowEstimate+3 {
+		t.Errorf("got %v for a truncated tree", c)
+	}
+}
+
+// newBudgetMux is newScanMux with a rate limit that first resets after reset,
+// reporting core requests remaining for each fetch in turn and the last one after that
+func newBudgetMux(reset time.Duration, core ...int) (*http.ServeMux, *int32) {
+	var calls, scanCalls int32
+	first := time.Now().Add(reset)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1)) - 1
+		if n >= len(core) {
+			n = len(core) - 1
+		}
+		next := first
+		if time.Now().After(first) {
+			next = time.Now().Add(time.Hour)
+		}
+		writeJSON(w, map[string]interface{}{"resources": map[string]interface{}{
+			"core": github.Rate{Limit: 5000, Remaining: core[n], Reset: github.Timestamp{Time: next}},
+		}})
+	})
+	mux.Handle("/", newScanMux(5000, &scanCalls))
+	return mux, &calls
+}
+
+func TestDetailWithOptionsBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy BudgetPolicy
+		core   []int
+		// fails is whether a *BudgetError is expected instead of any checks running
+		fails bool
+		// degraded is whether some detectors are expected to be skipped while the rest run
+		degraded bool
+		// fetches is the number of times the rate limit is expected to be fetched
+		fetches int32
+	}{
+		{"fail without the snapshot", BudgetFail, []int{snapshotCost.Core - 1}, true, false, 1},
+		{"fail without the detectors", BudgetFail, []int{snapshotCost.Core}, true, false, 1},
+		{"degrade without the snapshot", BudgetDegrade, []int{snapshotCost.Core - 1}, true, false, 1},
+		{"degrade without the detectors", BudgetDegrade, []int{snapshotCost.Core}, false, true, 1},
+		{"wait for the snapshot", BudgetWait, []int{snapshotCost.Core - 1, 5000}, false, false, 2},
+		{"wait for the detectors", BudgetWait, []int{snapshotCost.Core, 5000}, false, false, 2},
+		{"enough", BudgetFail, []int{5000}, false, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, calls := newBudgetMux(100*time.Millisecond, tt.core...)
+			client := newTestClient(t, mux)
+			d, err := DetailWithOptions(context.Background(), client, "o", "active", Options{Budget: tt.policy})
+			if got := atomic.LoadInt32(calls); got != tt.fetches {
+				t.Errorf("expected the rate limit to be fetched %d times, got %d", tt.fetches, got)
+			}
+
+			if tt.fails {
+				var budgetErr *BudgetError
+				if !errors.As(err, &budgetErr) {
+					t.Fatalf("expected a *BudgetError, got %v", err)
+				}
+				if len(budgetErr.Skipped) != len(Detectors()) {
+					t.Errorf("expected every detector to be skipped, got %v", budgetErr.Skipped)
+				}
+				if tt.core[0] < snapshotCost.Core && budgetErr.Needed != snapshotCost {
+					t.Errorf("expected the snapshot cost to be needed, got %v", budgetErr.Needed)
+				}
+				return
+			}
+
+			errs := new(Errors)
+			if err != nil && !errors.As(err, &errs) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			skipped := make(map[string]bool)
+			for _, det := range Detectors() {
+				if err := errs.Err(det.Name()); err != nil {
+					if !errors.Is(err, ErrRateLimitBudget) || !strings.HasPrefix(err.Error(), "skipped: ") {
+						t.Errorf("%s: unexpected error: %v", det.Name(), err)
+					}
+					skipped[det.Name()] = true
+				}
+			}
+			if !tt.degraded {
+				if len(skipped) != 0 || !d.InContributing {
+					t.Errorf("expected every detector to run, skipped %v and got %+v", skipped, d)
+				}
+				return
+			}
+			if !skipped[InContributingDetector] || d.InContributing {
+				t.Errorf("expected reading CONTRIBUTING.md to be skipped, skipped %v", skipped)
+			}
+			if skipped[KnownDetector] || skipped[BotFileDetector] {
+				t.Errorf("expected the detectors without API requests to run, skipped %v", skipped)
+			}
+		})
+	}
+}
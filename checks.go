@@ -9,207 +9,258 @@ package needcla
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/google/go-github/v43/github"
 )
 
-type check = func(context.Context) result
 type result struct {
 	d Details
 	e Errors
 }
-type checker struct {
+
+// Snapshot is a view of a repository's default branch that detectors run against
+type Snapshot struct {
 	branch string
 	repo   string
 	owner  string
 
+	// client is nil when the snapshot isn't backed by GitHub
 	client *github.Client
+	// forge is nil when the snapshot is of a local Source
+	forge Forge
+
+	src Source
+	// files caches file contents so detectors reading the same file only fetch it once
+	files *fileCache
+	// prs caches the recent pull requests so detectors sampling them only list them once
+	prs *prCache
+	// org caches the org's default community health files repository
+	org *orgCache
+	// statuses caches the statuses sampled from recent pull requests
+	statuses *statusCache
+	// statusContexts are the CLA status contexts to look for, or nil for DefaultStatusContexts
+	statusContexts []string
+	// config adjusts the built-in owners and matchers, or is nil to use them as they are
+	config *Config
+
+	// detector and evidence are set on the copy of the snapshot each detector runs against
+	detector string
+	evidence *evidenceLog
+}
 
-	tree *github.Tree
+// Owner returns the account that owns the repository
+func (s *Snapshot) Owner() string {
+	return s.owner
 }
 
-func newChecker(ctx context.Context, client *github.Client, owner, repo, branch string) (*checker, error) {
-	tree, _, err := client.Git.GetTree(ctx, owner, repo, branch, true)
+// Repo returns the name of the repository
+func (s *Snapshot) Repo() string {
+	return s.repo
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to get %s/%s tree: %v", owner, repo, err)
-	}
+// Branch returns the branch the snapshot was taken from
+func (s *Snapshot) Branch() string {
+	return s.branch
+}
+
+// Client returns the GitHub client used to take the snapshot,
+// or nil if the snapshot was taken from another Source
+func (s *Snapshot) Client() *github.Client {
+	return s.client
+}
+
+// Find returns the entry at path, or nil if it doesn't exist
+func (s *Snapshot) Find(ctx context.Context, path string) (*Entry, error) {
+	return s.find(ctx, path)
+}
+
+// List returns the entries directly inside dir, or nil if it doesn't exist
+func (s *Snapshot) List(ctx context.Context, dir string) ([]*Entry, error) {
+	return s.list(ctx, dir)
+}
+
+// Content returns the contents of the file at path, or nil if it doesn't exist
+func (s *Snapshot) Content(ctx context.Context, path string) ([]byte, error) {
+	return s.contentAtPath(ctx, path)
+}
 
-	return &checker{
-		client: client,
-		branch: branch,
-		repo:   repo,
-		owner:  owner,
-		tree:   tree,
-	}, nil
+// ReferencesCLA reports whether content references a CLA using the built-in string matchers
+func (s *Snapshot) ReferencesCLA(content []byte) (bool, error) {
+	return s.referencesCLAInContent(content)
 }
 
-func (c checker) isKnownCheck(ctx context.Context) result {
-	return result{
-		d: Details{
-			Known: c.isKnown(),
-		},
+// AddEvidence records what made the running detector find a CLA.
+// Detector defaults to the running detector's name.
+// Evidence from detectors that don't find a CLA is dropped.
+func (s *Snapshot) AddEvidence(e Evidence) {
+	if s.evidence == nil {
+		return
+	}
+	if e.Detector == "" {
+		e.Detector = s.detector
 	}
+	s.evidence.add(e)
 }
 
-func (c checker) isKnown() bool {
-	for _, o := range knownOwners {
-		if o == c.owner {
+func (s *Snapshot) isKnown() bool {
+	for _, o := range s.config.knownOwners() {
+		if o == s.owner {
+			s.AddEvidence(Evidence{Snippet: o})
 			return true
 		}
 	}
 	return false
 }
 
-func (c checker) hasCLATagCheck(ctx context.Context) result {
-	r, err := c.hasCLATag(ctx)
-	return result{
-		d: Details{
-			Tag: r,
-		},
-		e: Errors{
-			TagErr: err,
-		},
-	}
-}
-
-func (c checker) hasCLATag(ctx context.Context) (bool, error) {
-	opts := &github.PullRequestListOptions{
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-		State: "all",
-	}
-	prs, _, err := c.client.PullRequests.List(ctx, c.owner, c.repo, opts)
+func (s *Snapshot) hasCLATag(ctx context.Context) (bool, error) {
+	prs, err := s.pullRequests(ctx)
 	if err != nil {
-		return false, fmt.Errorf("error getting %s/%s PRs: %v", c.owner, c.repo, err)
+		return false, err
 	}
-	errs := make([]error, 0, 100)
 	for _, pr := range prs {
 		for _, label := range pr.Labels {
-			match, err := regexp.Match(prLabelMatcher, []byte(label.GetName()))
-			if err != nil {
-				errs = append(errs, err)
+			matcher := s.config.labelMatcher(label)
+			if matcher == "" {
+				continue
 			}
-			if match {
-				return true, nil
+			ev := Evidence{PullRequest: pr.Number, Label: label, Matcher: matcher}
+			if matcher == prLabelMatcher.Name {
+				// googlebot labels PRs once the author signs Google's CLA
+				ev.Provider = ProviderGoogle
 			}
+			s.AddEvidence(ev)
+			return true, nil
 		}
 	}
-	if len(errs) != 0 {
-		var lines []string
-		for _, err := range errs {
-			lines = append(lines, fmt.Sprintf("* %v", err))
-		}
-		return false, fmt.Errorf("%d errors(s) checking recent PR labels:\n\t%s", len(errs), strings.Join(lines, "\n\t"))
-	}
-
 	return false, nil
 }
 
-func (c checker) hasCLABotFileCheck(ctx context.Context) result {
-	r, err := c.hasCLABotFile(ctx)
-	return result{
-		d: Details{
-			BotFile: r,
-		},
-		e: Errors{
-			BotFileErr: err,
-		},
+// pullRequests returns the most recent 100 pull requests, or nil if the snapshot has no forge to ask
+func (s *Snapshot) pullRequests(ctx context.Context) ([]PullRequest, error) {
+	if s.forge == nil {
+		return nil, nil
+	}
+	if s.prs == nil {
+		return s.pullRequestsUncached(ctx)
 	}
+	s.prs.once.Do(func() {
+		s.prs.prs, s.prs.err = s.pullRequestsUncached(ctx)
+	})
+	return s.prs.prs, s.prs.err
 }
 
-func (c checker) hasCLABotFile(ctx context.Context) (bool, error) {
-	te, err := c.find(".clabot")
-	if te == nil || err != nil {
-		return false, err
+func (s *Snapshot) pullRequestsUncached(ctx context.Context) ([]PullRequest, error) {
+	prs, err := s.forge.PullRequests(ctx, s.owner, s.repo)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s/%s PRs: %v", s.owner, s.repo, err)
 	}
-	return true, nil
+	return prs, nil
 }
 
-func (c checker) referencesCLAInContributingCheck(ctx context.Context) result {
-	r, err := c.referencesCLAInContributing(ctx)
-	return result{
-		d: Details{
-			InContributing: r,
-		},
-		e: Errors{
-			InContributingErr: err,
-		},
-	}
+// prCache holds the result of listing pull requests once
+type prCache struct {
+	once sync.Once
+	prs  []PullRequest
+	err  error
 }
 
-func (c checker) referencesCLAInContributing(ctx context.Context) (bool, error) {
-	content, err := c.contentAtPath(ctx, "CONTRIBUTING.md")
-	if err != nil {
-		return false, fmt.Errorf("failed to check CONTRIBUTING.md: %v", err)
+func (s *Snapshot) hasCLABotFile(ctx context.Context) (bool, error) {
+	te, err := s.find(ctx, ".clabot")
+	if te == nil || err != nil {
+		return false, err
 	}
-	return c.referencesCLAInContent(content)
+	s.AddEvidence(Evidence{Path: te.Path, SHA: te.SHA, Provider: ProviderCLABot})
+	return true, nil
 }
 
-func (c checker) referencesCLAInREADMECheck(ctx context.Context) result {
-	r, err := c.referencesCLAInREADME(ctx)
-	return result{
-		d: Details{
-			InREADME: r,
-		},
-		e: Errors{
-			InREADMEErr: err,
-		},
+func (s *Snapshot) referencesCLAInContributing(ctx context.Context) (bool, error) {
+	e, content, err := s.readDoc(ctx, contributingDoc)
+	if err != nil {
+		return false, fmt.Errorf("failed to check CONTRIBUTING: %v", err)
+	}
+	if e == nil {
+		return false, nil
 	}
+	return s.referencesCLAInFile(e, content)
 }
 
-func (c checker) usesCLAAssistantActionCheck(ctx context.Context) result {
-	r, err := c.usesCLAAssistantAction(ctx)
-	return result{
-		d: Details{
-			Action: r,
-		},
-		e: Errors{
-			ActionErr: err,
-		},
+func (s *Snapshot) referencesCLAInREADME(ctx context.Context) (bool, error) {
+	e, content, err := s.readDoc(ctx, readmeDoc)
+	if err != nil {
+		return false, fmt.Errorf("failed to check README: %v", err)
+	}
+	if e == nil {
+		return false, nil
 	}
+	return s.referencesCLAInFile(e, content)
 }
 
-func (c checker) referencesCLAInREADME(ctx context.Context) (bool, error) {
-	content, err := c.contentAtPath(ctx, "README.md")
-	if err != nil {
-		return false, fmt.Errorf("failed to check README.md: %v", err)
+// statesNoCLA looks for CONTRIBUTING or README saying contributors don't need to sign a CLA
+func (s *Snapshot) statesNoCLA(ctx context.Context) (bool, error) {
+	for _, name := range []string{contributingDoc, readmeDoc} {
+		e, content, err := s.readDoc(ctx, name)
+		if err != nil {
+			return false, fmt.Errorf("failed to check %s: %v", name, err)
+		}
+		if e == nil {
+			continue
+		}
+		if ev := s.config.noCLAMatchers().matchFile(e.Path, content); ev != nil {
+			ev.at(e)
+			s.AddEvidence(*ev)
+			return true, nil
+		}
 	}
-	return c.referencesCLAInContent(content)
+	return false, nil
 }
 
-func (c checker) usesCLAAssistantAction(ctx context.Context) (bool, error) {
-	workflowsEntry, err := c.find(".github/workflows")
+func (s *Snapshot) usesCLAAssistantAction(ctx context.Context) (bool, error) {
+	workflows, err := s.list(ctx, ".github/workflows")
 	if err != nil {
 		if err == ErrTruncatedTree {
 			return false, fmt.Errorf("tree was truncated and .github/workflows was possibly missed")
 		}
 		return false, err
 	}
-	workflowsTree, _, err := c.client.Git.GetTree(ctx, c.owner, c.repo, workflowsEntry.GetSHA(), false)
+	ci, err := s.find(ctx, ".gitlab-ci.yml")
 	if err != nil {
-		return false, fmt.Errorf("failed to get %s/%s/master/.github/workflows tree: %v", c.owner, c.repo, err)
+		if err == ErrTruncatedTree {
+			return false, fmt.Errorf("tree was truncated and .gitlab-ci.yml was possibly missed")
+		}
+		return false, err
+	}
+	if ci != nil {
+		workflows = append(workflows, ci)
 	}
 
 	errs := make(map[string]error)
-	for _, e := range workflowsTree.Entries {
-		content, err := c.contentAtSHA(ctx, e.GetSHA())
-		if err != nil {
-			errs[e.GetPath()] = err
+	for _, e := range workflows {
+		if e.Dir {
 			continue
 		}
-		match, err := regexp.Match(actionMatcher, content)
+		content, err := s.readEntry(ctx, e)
 		if err != nil {
-			errs[e.GetPath()] = err
+			errs[e.Path] = err
 			continue
 		}
-		if match {
+		matcher := s.config.actionMatchers()
+		if e == ci {
+			matcher = gitlabCIMatcher
+		}
+		if ev := matcher.match(content); ev != nil {
+			ev.at(e)
+			if e == ci {
+				ev.Step = gitlabJob(content, ev.Line)
+			} else {
+				ev.Step = workflowStep(content, ev.Line)
+				ev.Provider = claActions[ev.Matcher]
+			}
+			s.AddEvidence(*ev)
 			return true, nil
 		}
 	}
@@ -225,97 +276,206 @@ func (c checker) usesCLAAssistantAction(ctx context.Context) (bool, error) {
 	return false, nil
 }
 
-func (c checker) checkAll(ctx context.Context) chan result {
-	checks := []check{
-		c.isKnownCheck,
-		c.hasCLATagCheck,
-		c.hasCLABotFileCheck,
-		c.referencesCLAInContributingCheck,
-		c.referencesCLAInREADMECheck,
-		c.usesCLAAssistantActionCheck,
+// detail runs detectors against s and merges their results, adding to any errors already in e
+func (s *Snapshot) detail(ctx context.Context, detectors []Detector, e *Errors) (Details, error) {
+	if s.files == nil {
+		s.files = &fileCache{files: make(map[string]*cachedFile)}
+	}
+	if s.prs == nil {
+		s.prs = new(prCache)
+	}
+	if s.statuses == nil {
+		s.statuses = new(statusCache)
+	}
+	if s.org == nil {
+		s.org = new(orgCache)
+	}
+	d := &Details{Branch: s.branch}
+	if r, ok := s.src.(revisioner); ok {
+		d.Commit = r.revision()
+	}
+	results := s.checkAll(ctx, detectors)
+	for result := range results {
+		d.merge(result.d)
+		e.merge(result.e)
+	}
+	// detectors finish in any order, so list evidence in the order they were registered
+	order := make(map[string]int, len(detectors))
+	for i, det := range detectors {
+		order[det.Name()] = i
+	}
+	sort.SliceStable(d.Evidence, func(i, j int) bool {
+		return order[d.Evidence[i].Detector] < order[d.Evidence[j].Detector]
+	})
+	sort.SliceStable(d.Unknown, func(i, j int) bool {
+		return order[d.Unknown[i]] < order[d.Unknown[j]]
+	})
+	for _, name := range []string{contributingDoc, readmeDoc} {
+		if e := s.files.entry(docKey(name)); e != nil {
+			if d.Docs == nil {
+				d.Docs = make(map[string]string)
+			}
+			d.Docs[name] = e.Path
+			if e.Repo != "" {
+				d.Docs[name] = e.Repo + "/" + e.Path
+			}
+		}
 	}
+	d.Provider = provider(d.Evidence)
+	d.SigningURLs = signingURLs(d.Evidence)
+	return *d, e.ErrOrNil()
+}
+
+func (s *Snapshot) checkAll(ctx context.Context, detectors []Detector) chan result {
 	results := make(chan result)
 	var wg sync.WaitGroup
-	wg.Add(len(checks))
+	wg.Add(len(detectors))
 
 	go func() {
 		wg.Wait()
 		close(results)
 	}()
 
-	for _, chk := range checks {
-		go func(chk check) {
+	for _, det := range detectors {
+		go func(det Detector) {
 			defer wg.Done()
-			results <- chk(ctx)
-		}(chk)
+			var r result
+			ds := *s
+			ds.detector, ds.evidence = det.Name(), new(evidenceLog)
+			found, err := det.Detect(ctx, &ds)
+			if errors.Is(err, ErrUnknown) {
+				r.d.Unknown, err = []string{det.Name()}, nil
+			}
+			r.d.set(det.Name(), found)
+			r.e.set(det.Name(), err)
+			if found {
+				r.d.Evidence = ds.evidence.list()
+			}
+			results <- r
+		}(det)
 	}
 
 	return results
 }
 
-func (c checker) find(path string) (*github.TreeEntry, error) {
-	// if tree.GetTruncated() {
-	// TODO
-	// response is too large
-	// need to do our own recursion to the path
-	// }
-	for _, e := range c.tree.Entries {
-		if e.GetPath() == path {
-			return e, nil
+func (s *Snapshot) find(ctx context.Context, path string) (*Entry, error) {
+	return s.src.Find(ctx, path)
+}
+
+// list returns the entries directly inside dir, or nil if it doesn't exist
+func (s *Snapshot) list(ctx context.Context, dir string) ([]*Entry, error) {
+	return s.src.List(ctx, dir)
+}
+
+func (s *Snapshot) contentAtPath(ctx context.Context, path string) ([]byte, error) {
+	_, content, err := s.read(ctx, path)
+	return content, err
+}
+
+// read returns the entry and contents of the file at path, or nils if it doesn't exist
+func (s *Snapshot) read(ctx context.Context, path string) (*Entry, []byte, error) {
+	if s.files == nil {
+		return s.readUncached(ctx, path)
+	}
+	return s.files.read(path, func() (*Entry, []byte, error) {
+		return s.readUncached(ctx, path)
+	})
+}
+
+// readEntry returns the contents of the file entry e, which was returned by find, list or readDoc
+func (s *Snapshot) readEntry(ctx context.Context, e *Entry) ([]byte, error) {
+	fetch := func() (*Entry, []byte, error) {
+		src := s.src
+		if e.Repo != "" {
+			org, err := s.orgDefaults(ctx)
+			if err != nil {
+				return nil, nil, err
+			}
+			if org == nil {
+				return nil, nil, fmt.Errorf("%s isn't available", e.Repo)
+			}
+			src = org
 		}
+		content, err := src.ReadFile(ctx, e)
+		return e, content, err
+	}
+	if s.files == nil {
+		_, content, err := fetch()
+		return content, err
 	}
-	if c.tree.GetTruncated() {
-		// TODO
-		// remove once we're handling truncated trees properly
-		return nil, ErrTruncatedTree
+	key := e.Path
+	if e.Repo != "" {
+		key = e.Repo + ":" + e.Path
 	}
-	return nil, nil
+	_, content, err := s.files.read(key, fetch)
+	return content, err
 }
 
-func (c checker) contentAtPath(ctx context.Context, path string) ([]byte, error) {
-	te, err := c.find(path)
-	if te == nil {
+func (s *Snapshot) readUncached(ctx context.Context, path string) (*Entry, []byte, error) {
+	e, err := s.find(ctx, path)
+	if e == nil {
 		if err == ErrTruncatedTree {
-			return nil, fmt.Errorf("tree was truncated and %s was possibly missed", path)
+			return nil, nil, fmt.Errorf("tree was truncated and %s was possibly missed", path)
 		}
-		return nil, err
+		return nil, nil, err
 	}
-	if te.GetType() != "blob" {
-		return nil, fmt.Errorf("%s wasn't a blob", path)
+	if e.Dir {
+		return nil, nil, fmt.Errorf("%s wasn't a blob", path)
 	}
-	b, _, err := c.client.Git.GetBlob(ctx, c.owner, c.repo, te.GetSHA())
-	if err != nil {
-		return nil, fmt.Errorf("error getting %s blob: %v", path, err)
-	}
-	if b.GetEncoding() != "base64" {
-		return nil, fmt.Errorf("blob is encoded %s, only base64 is supported", b.GetEncoding())
-	}
-	return base64.StdEncoding.DecodeString(b.GetContent())
+	content, err := s.src.ReadFile(ctx, e)
+	return e, content, err
 }
 
-func (c checker) contentAtSHA(ctx context.Context, sha string) ([]byte, error) {
-	b, _, err := c.client.Git.GetBlob(ctx, c.owner, c.repo, sha)
-	if err != nil {
-		return nil, fmt.Errorf("error getting %s blob: %v", sha, err)
-	}
-	if b.GetEncoding() != "base64" {
-		return nil, fmt.Errorf("blob is encoded %s, only base64 is supported", b.GetEncoding())
+// fileCache holds the result of reading each path once
+type fileCache struct {
+	mu    sync.Mutex
+	files map[string]*cachedFile
+}
+
+type cachedFile struct {
+	once    sync.Once
+	entry   *Entry
+	content []byte
+	err     error
+}
+
+// read returns the cached result for path, calling fetch if it's the first read
+func (c *fileCache) read(path string, fetch func() (*Entry, []byte, error)) (*Entry, []byte, error) {
+	c.mu.Lock()
+	f, ok := c.files[path]
+	if !ok {
+		f = new(cachedFile)
+		c.files[path] = f
 	}
-	return base64.StdEncoding.DecodeString(b.GetContent())
+	c.mu.Unlock()
+	f.once.Do(func() {
+		f.entry, f.content, f.err = fetch()
+	})
+	return f.entry, f.content, f.err
 }
 
-func (c checker) referencesCLAInContent(content []byte) (bool, error) {
-	var match bool
-	var err error
-	for _, matcher := range stringMatchers {
-		match, err = regexp.Match(matcher, content)
-		if err != nil {
-			return false, fmt.Errorf(`error matching against "%s": %v`, matcher, err)
-		}
-		if match {
-			return true, nil
-		}
+// entry returns the entry cached for path, or nil if it hasn't been read or doesn't exist.
+// It must only be called once every read of path has returned.
+func (c *fileCache) entry(path string) *Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f, ok := c.files[path]; ok {
+		return f.entry
 	}
+	return nil
+}
 
-	return false, nil
+func (s *Snapshot) referencesCLAInContent(content []byte) (bool, error) {
+	return s.config.claMatchers().match(content) != nil, nil
+}
+
+// referencesCLAInFile is referencesCLAInContent, recording where in the file e the match was as evidence
+func (s *Snapshot) referencesCLAInFile(e *Entry, content []byte) (bool, error) {
+	ev := s.config.claMatchers().matchFile(e.Path, content)
+	if ev == nil {
+		return false, nil
+	}
+	ev.at(e)
+	s.AddEvidence(*ev)
+	return true, nil
 }
@@ -10,14 +10,72 @@ package needcla
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/google/go-github/v43/github"
 )
 
-var stringMatchers = []string{"\bCLA\b", "Contributor License Agreement"}
-var actionMatcher = "uses:[[:space:]]*?cla-assistant/github-action"
-var prLabelMatcher = "cla:[[:space:]]*?[yes|no]"
+// claActions are the GitHub Actions that run CLA checks, and the CLA service each uses
+var claActions = map[string]string{
+	"cla-assistant/github-action": ProviderCLAAssistant,
+}
+
+// actionMatcher finds CI steps using the actions in claActions
+var actionMatcher = func() matcherSet {
+	var ms matcherSet
+	for action := range claActions {
+		ms = append(ms, usesAction(action))
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Name < ms[j].Name })
+	return ms
+}()
+
+// usesAction returns a matcher for workflow steps using action, like "cla-assistant/github-action@v2", named after it
+func usesAction(action string) Matcher {
+	return mustMatcher(action, `(?im)uses:[[:space:]]*['"]?`+regexp.QuoteMeta(action)+`(?:@|/|['"]|[[:space:]]|$)`)
+}
+
+// isActionName reports whether s names an action, like "owner/name" or "owner/repo/path"
+func isActionName(s string) bool {
+	parts := strings.Split(s, "/")
+	for _, p := range parts {
+		if p == "" || strings.ContainsAny(p, " \t@") {
+			return false
+		}
+	}
+	return len(parts) >= 2
+}
+
+// gitlabCIMatcher finds GitLab CI jobs that look like CLA checks
+var gitlabCIMatcher = matcherSet{mustMatcher("gitlab-cla-job", `(?im)^(?:[\w.]*[-_.])?cla(?:[-_.][\w.-]*)?[[:space:]]*:|cla-assistant`)}
+
+// prLabelMatcher finds the labels CLA bots put on PRs, like googlebot's "cla: yes" and "cla: no"
+var prLabelMatcher = mustMatcher("cla-label", `(?i)^cla[:/][[:space:]]*(?:yes|no|signed|not[- ]signed)$`)
+
+// configLabel is the matcher name recorded for labels added by a Config
+const configLabel = "config-label"
+
+// dcoActionMatcher finds CI steps using a DCO check action, like tisonkun/actions-dco or christophebedard/dco-check
+var dcoActionMatcher = matcherSet{mustMatcher("dco-action", `(?im)uses:[[:space:]]*['"]?[\w.-]+/[\w.-]*dco[\w.-]*`)}
+
+// dcoMatchers find DCO sign-off policies in contributing guides
+var dcoMatchers = matcherSet{
+	Phrase("signed-off-by", "Signed-off-by"),
+	Phrase("dco-phrase", "Developer Certificate of Origin"),
+	mustMatcher("dco-acronym", `\bDCO\b`),
+}
+
+// signedOffMatcher finds Signed-off-by trailers in commit messages
+var signedOffMatcher = regexp.MustCompile(`(?m)^Signed-off-by: .+ <.+>`)
+
+// easyCLAMatchers find EasyCLA and its bot in config files, like linux-foundation-easycla
+var easyCLAMatchers = matcherSet{mustMatcher("easycla", `(?i)\beasy-?cla\b`), mustMatcher("lfcla", `(?i)\blfcla\.com\b`)}
+
+// easyCLALinkMatchers find EasyCLA badges and signing links in docs, like
+// https://api.easycla.lfx.linuxfoundation.org/v2/repository-provider/github/sign/...
+var easyCLALinkMatchers = matcherSet{mustMatcher("easycla-link", `(?i)https?://[^\s)"'<>\]]*(?:easy-?cla|lfcla\.com)`)}
 
 func Check(client *github.Client, owner string, repo string) (bool, error) {
 	return CheckWithContext(context.Background(), client, owner, repo)
@@ -33,37 +91,136 @@ func Detail(client *github.Client, owner string, repo string) (Details, error) {
 }
 
 func DetailWithContext(ctx context.Context, client *github.Client, owner string, repo string) (Details, error) {
-	limits, _, err := client.RateLimits(ctx)
+	return DetailWithOptions(ctx, client, owner, repo, Options{})
+}
+
+// Options configures how DetailWithOptions checks a repository
+type Options struct {
+	// Budget decides what happens when the GitHub rate limit can't cover every check
+	Budget BudgetPolicy
+	// RateBudget is shared with other checks using the same token, if they run at the same time.
+	// If nil, each check plans against the whole rate limit.
+	RateBudget *RateBudget
+	// StatusContexts are the commit status contexts and check run names that show a CLA.
+	// If nil, DefaultStatusContexts is used.
+	StatusContexts []string
+	// Config adjusts the built-in heuristics, like one loaded with LoadConfig.
+	// If nil, they're used as they are.
+	Config *Config
+}
+
+func DetailWithOptions(ctx context.Context, client *github.Client, owner string, repo string, opts Options) (Details, error) {
+	detectors := opts.Config.detectors(Detectors())
+	budget := opts.RateBudget
+	if budget == nil {
+		budget = new(RateBudget)
+	}
+	limits, err := budget.reserve(ctx, client, func(remaining Cost) Cost {
+		if snapshotCost.fits(remaining) {
+			return snapshotCost
+		}
+		return Cost{}
+	})
 	if err != nil {
-		return Details{}, fmt.Errorf("failed to get github rate limit: %w", err)
+		return Details{}, err
 	}
-	if limits.Core.Remaining < 10 {
-		// TODO: count actual API calls we'll make
-		return Details{}, fmt.Errorf("remaining github rate limit too low")
+	if !snapshotCost.fits(limits.remaining()) {
+		budgetErr := &BudgetError{
+			Needed:    snapshotCost,
+			Remaining: limits.remaining(),
+			Reset:     limits.reset(snapshotCost),
+			Skipped:   detectorNames(detectors),
+		}
+		if opts.Budget != BudgetWait {
+			return Details{}, budgetErr
+		}
+		if err := waitUntil(ctx, budgetErr.Reset); err != nil {
+			return Details{}, err
+		}
+		if _, err := budget.reserve(ctx, client, func(Cost) Cost { return snapshotCost }); err != nil {
+			return Details{}, err
+		}
 	}
 
-	r, resp, _ := client.Repositories.Get(ctx, owner, repo)
-	if resp.StatusCode == http.StatusNotFound {
-		return Details{}, fmt.Errorf("%s/%s: %w", owner, repo, ErrNotFound)
+	gh := GitHubForge(client)
+	def, err := gh.DefaultBranch(ctx, owner, repo)
+	if err != nil {
+		return Details{}, err
+	}
+	src, err := gh.Source(ctx, owner, repo, def)
+	if err != nil {
+		return Details{}, fmt.Errorf("failed to create snapshot: %w", err)
 	}
-	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return Details{}, ErrInvalidToken
+	s := &Snapshot{
+		owner:  owner,
+		repo:   repo,
+		branch: def,
+		client: client,
+		forge:  gh,
+		src:    src,
+
+		statusContexts: opts.Config.statusContexts(opts.StatusContexts),
+		config:         opts.Config,
 	}
-	def := r.GetDefaultBranch()
 
-	c, err := newChecker(ctx, client, owner, repo, def)
+	e := new(Errors)
+	var run, skipped []Detector
+	limits, err = budget.reserve(ctx, client, func(remaining Cost) Cost {
+		run, skipped = plan(detectors, s, remaining)
+		if len(skipped) != 0 && opts.Budget != BudgetDegrade {
+			return Cost{}
+		}
+		return planCost(run, s)
+	})
 	if err != nil {
-		return Details{}, fmt.Errorf("failed to create checker: %w", err)
+		return Details{}, err
 	}
-	var (
-		d = new(Details)
-		e = new(Errors)
-	)
-	results := c.checkAll(ctx)
-	for result := range results {
-		d.merge(result.d)
-		e.merge(result.e)
+	if len(skipped) != 0 {
+		needed := planCost(detectors, s)
+		budgetErr := &BudgetError{
+			Needed:    needed,
+			Remaining: limits.remaining(),
+			Reset:     limits.reset(needed),
+			Skipped:   detectorNames(skipped),
+		}
+		switch opts.Budget {
+		case BudgetFail:
+			budgetErr.Skipped = detectorNames(detectors)
+			return Details{}, budgetErr
+		case BudgetWait:
+			if err := waitUntil(ctx, budgetErr.Reset); err != nil {
+				return Details{}, err
+			}
+			if _, err := budget.reserve(ctx, client, func(Cost) Cost { return needed }); err != nil {
+				return Details{}, err
+			}
+			run, skipped = detectors, nil
+		}
+		for _, det := range skipped {
+			e.set(det.Name(), fmt.Errorf("skipped: %w", budgetErr))
+		}
 	}
 
-	return *d, e.ErrOrNil()
+	return s.detail(ctx, run, e)
+}
+
+// DetailSource checks the repository files in src without making any GitHub API requests.
+// owner and repo may be empty if they aren't known, in which case the known owner check won't match.
+// Detectors that need the GitHub API, like the PR label check, report no CLA.
+func DetailSource(ctx context.Context, src Source, owner string, repo string) (Details, error) {
+	return DetailSourceWithOptions(ctx, src, owner, repo, Options{})
+}
+
+// DetailSourceWithOptions is DetailSource with options.
+// The budget options don't apply, since no GitHub API requests are made.
+func DetailSourceWithOptions(ctx context.Context, src Source, owner string, repo string, opts Options) (Details, error) {
+	s := &Snapshot{
+		owner: owner,
+		repo:  repo,
+		src:   src,
+
+		statusContexts: opts.Config.statusContexts(opts.StatusContexts),
+		config:         opts.Config,
+	}
+	return s.detail(ctx, opts.Config.detectors(Detectors()), new(Errors))
 }
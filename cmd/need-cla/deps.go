@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/peterbourgon/ff/v3"
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+// runDeps checks the repositories of every dependency in a project's manifests
+func runDeps(args []string) {
+	var indirect bool
+	fs := flag.NewFlagSet("need-cla deps", flag.ExitOnError)
+	fs.StringVar(&token, "token", "", "GitHub personal access token")
+	fs.StringVar(&budget, "budget", "fail", "what to do when the rate limit is too low: fail, wait or degrade")
+	fs.BoolVar(&indirect, "indirect", false, "include indirect Go dependencies")
+	fs.StringVar(&configPath, "config", "", "TOML file adjusting the built-in owners, status contexts, labels, actions, bots, matchers and checks")
+	fs.Usage = func() {
+		fmt.Println("Usage of ./need-cla deps: need-cla deps [-h] [-token PERSONAL_ACCESS_TOKEN] [-budget fail|wait|degrade] [-indirect] [-config FILE] [PATH]")
+		fmt.Println("  -token string\n  \tGitHub personal access token, can also be passed as CLA_TOKEN env var")
+		fmt.Println("  -budget string\n  \tWhat to do when the GitHub rate limit can't cover every check: fail, wait until it resets, or degrade by skipping expensive checks (default \"fail\")")
+		fmt.Println("  -indirect\n  \tInclude dependencies go.mod marks // indirect")
+		fmt.Println("  -config string\n  \tTOML file that adds or removes known owners, status contexts, labels, actions, bots and matchers, or disables checks")
+		fmt.Println("\nPATH is a project directory containing go.mod, package.json, requirements.txt, Cargo.toml or pom.xml (default \".\")")
+	}
+	ff.Parse(fs, args, ff.WithEnvVarPrefix("CLA"))
+	policy, err := needcla.ParseBudgetPolicy(budget)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	loadConfig()
+	path := "."
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		path = fs.Arg(0)
+	default:
+		fmt.Printf("expected a project directory, got %d arguments: %s\n", fs.NArg(), strings.Join(fs.Args(), " "))
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	all, err := needcla.ReadDependencies(ctx, needcla.NewDirSource(path))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	var deps []needcla.Dependency
+	for _, d := range all {
+		if indirect || !d.Indirect {
+			deps = append(deps, d)
+		}
+	}
+	if len(deps) == 0 {
+		fmt.Printf("I found no dependencies in %s\n", path)
+		return
+	}
+
+	// every GitHub repository shares one client and rate limit, like the repositories of an org scan
+	client := github.NewClient(httpClient(ctx))
+	opts := needcla.Options{Budget: policy, RateBudget: new(needcla.RateBudget), Config: config}
+	results := needcla.DetailDependencies(ctx, nil, deps, func(ctx context.Context, ref needcla.Reference) (needcla.Details, error) {
+		kind := needcla.ForgeKind(ref.Host)
+		if kind == needcla.ForgeGitHub {
+			return needcla.DetailWithOptions(ctx, client, ref.Owner, ref.Repo, opts)
+		}
+		f, err := needcla.NewForge(nil, kind, "https://"+ref.Host, token)
+		if err != nil {
+			return needcla.Details{}, err
+		}
+		return needcla.DetailForgeWithOptions(ctx, f, ref.Owner, ref.Repo, needcla.Options{Budget: policy, Config: config})
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tCLA\tDCO\tDEPENDENCIES")
+	var failed []string
+	for _, r := range results {
+		names := make([]string, 0, len(r.Dependencies))
+		for _, d := range r.Dependencies {
+			names = append(names, d.Name)
+		}
+		repo := r.Reference.String()
+		if r.Reference == (needcla.Reference{}) {
+			repo = "?"
+		}
+		dco := "no"
+		if r.Details.Result(needcla.DCODetector) {
+			dco = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", repo, claStatus(r), dco, strings.Join(names, ", "))
+		if r.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", repo, r.Err))
+		}
+	}
+	w.Flush()
+	if len(failed) > 0 {
+		fmt.Printf("\nI couldn't check every repository:\n* %s\n", strings.Join(failed, "\n* "))
+	}
+}
+
+// claStatus summarizes a result, treating partial failures as unknown unless a CLA was found anyway
+func claStatus(r needcla.DependencyResult) string {
+	v := r.Details.Verdict()
+	if r.Err != nil && v != needcla.VerdictRequired {
+		return "unknown"
+	}
+	return v.String()
+}
@@ -9,9 +9,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 
@@ -21,32 +23,126 @@ import (
 	"golang.org/x/oauth2"
 )
 
-var token string
+var (
+	token   string
+	budget  string
+	dir     string
+	forge   string
+	baseURL string
+	format  string
+	explain bool
+
+	configPath string
+	// config is loaded from configPath, or nil to use the built-in heuristics as they are
+	config *needcla.Config
+)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "deps" {
+		runDeps(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "org" {
+		runOrg(os.Args[2:])
+		return
+	}
 	fs := flag.NewFlagSet("need-cla", flag.ExitOnError)
 	fs.StringVar(&token, "token", "", "GitHub personal access token")
+	fs.StringVar(&budget, "budget", "fail", "what to do when the rate limit is too low: fail, wait or degrade")
+	fs.StringVar(&dir, "dir", "", "check a local checkout instead of GitHub")
+	fs.StringVar(&forge, "forge", "", "where the repository is hosted: github, gitlab, gitea or bitbucket")
+	fs.StringVar(&baseURL, "url", "", "base URL of a self-hosted forge")
+	fs.StringVar(&format, "format", "text", "output format: text or json")
+	fs.BoolVar(&explain, "explain", false, "show the evidence behind each check that found a CLA")
+	fs.StringVar(&configPath, "config", "", "TOML file adjusting the built-in owners, status contexts, labels, actions, bots, matchers and checks")
 	fs.Usage = func() {
-		fmt.Println("Usage of ./need-cla: need-cla [-h] [-token GITHUB_PERSONAL_ACCESS_TOKEN] owner repo")
-		fmt.Println("  -token string\n  \tGitHub personal access token, can also be passed as CLA_TOKEN env var")
+		fmt.Println("Usage of ./need-cla: need-cla [-h] [-token PERSONAL_ACCESS_TOKEN] [-forge KIND] [-url BASE_URL] [-budget fail|wait|degrade] [-format text|json] [-explain] [-config FILE] owner repo")
+		fmt.Println("              or: need-cla [-h] [-token PERSONAL_ACCESS_TOKEN] [-forge KIND] REPOSITORY")
+		fmt.Println("              or: need-cla [-h] -dir PATH [owner repo]")
+		fmt.Println("              or: need-cla deps [-h] [PATH]")
+		fmt.Println("              or: need-cla org [-h] owner")
+		fmt.Println("  -token string\n  \tPersonal access token for the forge, can also be passed as CLA_TOKEN env var")
+		fmt.Println("  -forge string\n  \tWhere the repository is hosted: github, gitlab, gitea or bitbucket (default from the URL host, or \"github\")")
+		fmt.Println("  -url string\n  \tBase URL of a self-hosted forge")
+		fmt.Println("  -budget string\n  \tWhat to do when the GitHub rate limit can't cover every check: fail, wait until it resets, or degrade by skipping expensive checks (default \"fail\")")
+		fmt.Println("  -dir string\n  \tCheck a local checkout without calling the GitHub API, owner and repo are optional")
+		fmt.Println("  -format string\n  \tOutput format: text, or json for scripts (default \"text\")")
+		fmt.Println("  -explain\n  \tShow the file, line and text, or PR and label, that made each check find a CLA")
+		fmt.Println("  -config string\n  \tTOML file that adds or removes known owners, status contexts, labels, actions, bots and matchers, or disables checks")
+		fmt.Println("\nREPOSITORY can be owner/repo, a URL like https://github.com/owner/repo, a git remote like git@github.com:owner/repo.git, or a Go import path")
 	}
 	ff.Parse(fs, os.Args[1:], ff.WithEnvVarPrefix("CLA"))
-	var httpClient *http.Client
-	if token == "" {
-		httpClient = nil
-	} else {
-		ctx := context.Background()
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: token},
-		)
-		httpClient = oauth2.NewClient(ctx, ts)
+	policy, err := needcla.ParseBudgetPolicy(budget)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
+	loadConfig()
+	if format != "text" && format != "json" {
+		fmt.Printf("unknown format %q, expected text or json\n", format)
+		os.Exit(2)
+	}
+	var owner, repo, host string
+	switch fs.NArg() {
+	case 0:
+		if dir == "" {
+			fs.Usage()
+			os.Exit(2)
+		}
+	case 1:
+		ref, err := needcla.ParseReference(fs.Arg(0))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		owner, repo, host = ref.Owner, ref.Repo, ref.Host
+		if forge == "" {
+			forge = needcla.ForgeKind(ref.Host)
+		}
+		if baseURL == "" && needcla.ForgeKind(ref.Host) != needcla.ForgeGitHub {
+			baseURL = "https://" + ref.Host
+		}
+	case 2:
+		owner, repo = fs.Arg(0), fs.Arg(1)
+	default:
+		fmt.Printf("expected a repository, got %d arguments: %s\n", fs.NArg(), strings.Join(fs.Args(), " "))
+		os.Exit(2)
+	}
+	if forge == "" {
+		forge = needcla.ForgeGitHub
+	}
+	if host == "" && dir == "" {
+		u := baseURL
+		if u == "" {
+			u = needcla.ForgeURL(forge)
+		}
+		if u, err := url.Parse(u); err == nil {
+			host = u.Host
+		}
+	}
+	name := fmt.Sprintf("%s/%s", owner, repo)
 
-	client := github.NewClient(httpClient)
-	owner := fs.Arg(0)
-	repo := fs.Arg(1)
-
-	d, err := needcla.Detail(client, owner, repo)
+	var d needcla.Details
+	switch {
+	case dir != "":
+		if owner == "" {
+			name = dir
+		}
+		d, err = needcla.DetailSourceWithOptions(context.Background(), needcla.NewDirSource(dir), owner, repo, needcla.Options{Config: config})
+	case forge == needcla.ForgeGitHub && baseURL == "":
+		d, err = detail(owner, repo, policy)
+	default:
+		d, err = detailForge(owner, repo, policy)
+	}
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(needcla.NewReport(needcla.Reference{Host: host, Owner: owner, Repo: repo}, d, err))
+		if _, ok := err.(*needcla.Errors); err != nil && !ok {
+			os.Exit(1)
+		}
+		return
+	}
 	if err != nil {
 		fmt.Println(err)
 		if _, ok := err.(*needcla.Errors); !ok {
@@ -56,44 +152,108 @@ func main() {
 	}
 
 	lines := []string{
-		fmt.Sprintf("I found that %s/%s:", owner, repo),
-		fmt.Sprintf("* %s %s a known CLA requirer", owner, is(d.Known)),
-		fmt.Sprintf("* CONTRIBUTING.md %s reference a CLA", does(d.InContributing)),
-		fmt.Sprintf("* README.md %s reference a CLA", does(d.InREADME)),
-		fmt.Sprintf("* %s use the cla-bot Github Action", does(d.Action)),
-		fmt.Sprintf("* PRs %s have \"cla\" tags", do(d.Tag)),
-		fmt.Sprintf("* .clabot file %s exist", does(d.BotFile)),
+		fmt.Sprintf("I found that %s:", name),
+	}
+	for _, det := range needcla.Detectors() {
+		if det.Name() == needcla.DCODetector {
+			continue
+		}
+		mark := symbol(d.Result(det.Name()))
+		if d.IsUnknown(det.Name()) {
+			mark = "?"
+		}
+		lines = append(lines, fmt.Sprintf("* [%s] %s", mark, det.Description()))
+		lines = append(lines, evidence(d, det.Name())...)
 	}
 
-	fmt.Printf("[%s] I think %s/%s %s need a CLA signed before contributing.\n\n", symbol(d.Required()), owner, repo, does(d.Required()))
+	confidence := int(d.Confidence()*100 + 0.5)
+	switch d.Verdict() {
+	case needcla.VerdictUncertain:
+		fmt.Printf("[?] I'm not sure whether %s needs a CLA signed before contributing (%d%% confidence).\n\n", name, confidence)
+	default:
+		fmt.Printf("[%s] I think %s %s need a CLA signed before contributing (%d%% confidence).\n\n", symbol(d.Required()), name, does(d.Required()), confidence)
+	}
+	switch {
+	case d.Provider == needcla.ProviderCustom:
+		fmt.Print("The CLA looks to be managed by the project itself.\n\n")
+	case d.Provider != "":
+		fmt.Printf("The CLA looks to be managed by %s.\n\n", d.Provider)
+	}
+	if len(d.SigningURLs) != 0 {
+		fmt.Printf("Sign it at:\n\t* %s\n\n", strings.Join(d.SigningURLs, "\n\t* "))
+	}
 	fmt.Print(strings.Join(lines, "\n\t"))
 
+	// a DCO is a different approval path, so it gets its own verdict
+	fmt.Printf("\n\n[%s] I think %s %s ask for commits to be signed off under a Developer Certificate of Origin (DCO).", symbol(d.Result(needcla.DCODetector)), name, does(d.Result(needcla.DCODetector)))
+	if dco := evidence(d, needcla.DCODetector); len(dco) != 0 {
+		fmt.Print("\n\t" + strings.Join(dco, "\n\t"))
+	}
+	fmt.Println()
 }
 
-func symbol(b bool) string {
-	if b {
-		return "✓"
+// evidence returns lines explaining what the detector with the given name found, if -explain was passed
+func evidence(d needcla.Details, name string) []string {
+	if !explain {
+		return nil
 	}
-	return "✗"
+	var lines []string
+	for _, ev := range d.Evidence {
+		if ev.Detector == name {
+			lines = append(lines, fmt.Sprintf("      %s", ev))
+		}
+	}
+	return lines
 }
 
-func is(b bool) string {
-	if b {
-		return "IS"
+// httpClient returns a client that authenticates with token, or nil to make anonymous requests
+func httpClient(ctx context.Context) *http.Client {
+	if token == "" {
+		return nil
 	}
-	return "IS NOT"
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+	return oauth2.NewClient(ctx, ts)
 }
 
-func does(b bool) string {
+func detail(owner, repo string, policy needcla.BudgetPolicy) (needcla.Details, error) {
+	client := github.NewClient(httpClient(context.Background()))
+	return needcla.DetailWithOptions(context.Background(), client, owner, repo, needcla.Options{Budget: policy, Config: config})
+}
+
+func detailForge(owner, repo string, policy needcla.BudgetPolicy) (needcla.Details, error) {
+	f, err := needcla.NewForge(nil, forge, baseURL, token)
+	if err != nil {
+		return needcla.Details{}, err
+	}
+	// the budget only applies to GitHub Enterprise, other forges ignore it
+	return needcla.DetailForgeWithOptions(context.Background(), f, owner, repo, needcla.Options{Budget: policy, Config: config})
+}
+
+// loadConfig loads the -config file into config, exiting if it's invalid
+func loadConfig() {
+	if configPath == "" {
+		return
+	}
+	c, err := needcla.LoadConfig(configPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	config = c
+}
+
+func symbol(b bool) string {
 	if b {
-		return "DOES"
+		return "✓"
 	}
-	return "DOES NOT"
+	return "✗"
 }
 
-func do(b bool) string {
+func does(b bool) string {
 	if b {
-		return "DO"
+		return "DOES"
 	}
-	return "DO NOT"
+	return "DOES NOT"
 }
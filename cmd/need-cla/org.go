@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/peterbourgon/ff/v3"
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+// runOrg checks every repository of a GitHub organization, printing each result as it completes
+func runOrg(args []string) {
+	var opts needcla.ScanOptions
+	fs := flag.NewFlagSet("need-cla org", flag.ExitOnError)
+	fs.StringVar(&token, "token", "", "GitHub personal access token")
+	fs.StringVar(&budget, "budget", "fail", "what to do when the rate limit is too low: fail, wait or degrade")
+	fs.IntVar(&opts.Workers, "workers", 4, "how many repositories to check at once")
+	fs.BoolVar(&opts.SkipArchived, "skip-archived", false, "leave out archived repositories")
+	fs.BoolVar(&opts.SkipForks, "skip-forks", false, "leave out forks")
+	fs.StringVar(&format, "format", "text", "output format: text or json")
+	fs.StringVar(&configPath, "config", "", "TOML file adjusting the built-in owners, status contexts, labels, actions, bots, matchers and checks")
+	fs.Usage = func() {
+		fmt.Println("Usage of ./need-cla org: need-cla org [-h] [-token PERSONAL_ACCESS_TOKEN] [-budget fail|wait|degrade] [-workers N] [-skip-archived] [-skip-forks] [-format text|json] [-config FILE] owner")
+		fmt.Println("  -token string\n  \tGitHub personal access token, can also be passed as CLA_TOKEN env var")
+		fmt.Println("  -budget string\n  \tWhat to do when the GitHub rate limit can't cover a repository's checks: fail, wait until it resets, or degrade by skipping expensive checks (default \"fail\")")
+		fmt.Println("  -workers int\n  \tHow many repositories to check at once (default 4)")
+		fmt.Println("  -skip-archived\n  \tLeave out archived repositories")
+		fmt.Println("  -skip-forks\n  \tLeave out forks")
+		fmt.Println("  -format string\n  \tOutput format: text, or json for a report per line (default \"text\")")
+		fmt.Println("  -config string\n  \tTOML file that adds or removes known owners, status contexts, labels, actions, bots and matchers, or disables checks")
+	}
+	ff.Parse(fs, args, ff.WithEnvVarPrefix("CLA"))
+	policy, err := needcla.ParseBudgetPolicy(budget)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	opts.Budget = policy
+	loadConfig()
+	opts.Config = config
+	if format != "text" && format != "json" {
+		fmt.Printf("unknown format %q, expected text or json\n", format)
+		os.Exit(2)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	org := fs.Arg(0)
+
+	ctx := context.Background()
+	client := github.NewClient(httpClient(ctx))
+	var required, failed int
+	enc := json.NewEncoder(os.Stdout)
+	err = needcla.ScanOrg(ctx, client, org, opts, func(r needcla.ScanResult) {
+		switch {
+		case format == "json":
+			enc.Encode(needcla.NewReport(needcla.Reference{Host: "github.com", Owner: org, Repo: r.Repo}, r.Details, r.Err))
+		case r.Details.Required():
+			required++
+			fmt.Printf("[%s] %s/%s\n", symbol(true), org, r.Repo)
+		case r.Err != nil:
+			failed++
+			fmt.Printf("[?] %s/%s: %v\n", org, r.Repo, r.Err)
+		default:
+			fmt.Printf("[%s] %s/%s\n", symbol(false), org, r.Repo)
+		}
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if format == "json" {
+		return
+	}
+	fmt.Printf("\nI think %d repositories in %s need a CLA signed before contributing, and couldn't check %d.\n", required, org, failed)
+}
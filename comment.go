@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// commentSample is how many recent external contributors' pull requests have their comments checked
+	commentSample = 5
+	// commentLimit is how many comments are fetched from each pull request; CLA bots comment early
+	commentLimit = 30
+)
+
+// claBots are the logins of bots that comment on pull requests asking for a CLA, and their providers
+var claBots = map[string]string{
+	"claassistant":                    ProviderCLAAssistant,
+	"googlebot":                       ProviderGoogle,
+	"google-cla":                      ProviderGoogle,
+	"msftclas":                        ProviderMicrosoft,
+	"microsoft-cla":                   ProviderMicrosoft,
+	"microsoft-github-policy-service": ProviderMicrosoft,
+	"linux-foundation-easycla":        ProviderEasyCLA,
+	"cla-bot":                         ProviderCLABot,
+	// the CLA Assistant Lite action comments as whichever account runs it, usually github-actions
+	"github-actions": "",
+}
+
+// hasCLABotComment looks for CLA bots asking external contributors to sign a CLA on recent pull requests
+func (s *Snapshot) hasCLABotComment(ctx context.Context) (bool, error) {
+	lister, ok := s.forge.(CommentLister)
+	if !ok {
+		return false, nil
+	}
+	prs, err := s.pullRequests(ctx)
+	if err != nil {
+		return false, err
+	}
+	var checked int
+	for _, pr := range prs {
+		if !pr.External {
+			continue
+		}
+		if checked == commentSample {
+			break
+		}
+		checked++
+		comments, err := lister.Comments(ctx, s.owner, s.repo, pr.Number, commentLimit)
+		if err != nil {
+			return false, fmt.Errorf("error getting PR #%d comments: %v", pr.Number, err)
+		}
+		for _, c := range comments {
+			provider, ok := s.config.claBot(c.Author)
+			if !ok {
+				continue
+			}
+			// bots comment in Markdown, like "please sign our Contributor License Agreement" under a badge
+			body := parseMarkdown([]byte(c.Body))
+			ev := s.config.claMatchers().matchDocument(body)
+			if ev == nil {
+				continue
+			}
+			ev.PullRequest, ev.Line, ev.Provider = pr.Number, 0, provider
+			if links := documentSigningLinks(body); len(links) != 0 {
+				ev.URL = links[0].url
+				if provider == "" {
+					ev.Provider = links[0].provider
+				}
+			}
+			s.AddEvidence(*ev)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// claBot reports whether login is a known CLA bot, and the provider it belongs to if it's known
+func claBot(login string) (string, bool) {
+	login = strings.ToLower(strings.TrimSuffix(login, "[bot]"))
+	provider, ok := claBots[login]
+	return provider, ok
+}
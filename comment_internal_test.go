@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-github/v43/github"
+)
+
+func TestHasCLABotComment(t *testing.T) {
+	var fetched []int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		prs := []*github.PullRequest{
+			{Number: github.Int(10), AuthorAssociation: github.String("MEMBER")},
+		}
+		for n := 9; n > 0; n-- {
+			prs = append(prs, &github.PullRequest{Number: github.Int(n), AuthorAssociation: github.String("FIRST_TIME_CONTRIBUTOR")})
+		}
+		writeJSON(w, prs)
+	})
+	mux.HandleFunc("/repos/o/r/issues/", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		fmt.Sscanf(r.URL.Path, "/repos/o/r/issues/%d/comments", &n)
+		fetched = append(fetched, n)
+		if r.URL.Query().Get("per_page") != fmt.Sprint(commentLimit) {
+			t.Errorf("expected at most %d comments to be fetched, got per_page=%s", commentLimit, r.URL.Query().Get("per_page"))
+		}
+		comments := []*github.IssueComment{
+			{User: &github.User{Login: github.String("maintainer")}, Body: github.String("Thanks! Do you have a CLA?")},
+		}
+		if n == 5 {
+			comments = append(comments, &github.IssueComment{
+				User: &github.User{Login: github.String("CLAassistant")},
+				Body: github.String("[![CLA assistant check](https://cla-assistant.io/pull/badge/not_signed)](https://cla-assistant.io/o/r?pullRequest=5) <br/>" +
+					"Thank you for your submission! We ask that you sign our [Contributor License Agreement](https://cla-assistant.io/o/r?pullRequest=5) before we can accept your contribution."),
+			})
+		}
+		writeJSON(w, comments)
+	})
+
+	s := &Snapshot{
+		owner:    "o",
+		repo:     "r",
+		forge:    GitHubForge(newTestClient(t, mux)),
+		src:      NewFSSource(fstest.MapFS{}),
+		detector: CommentDetector,
+		evidence: new(evidenceLog),
+	}
+	found, err := s.hasCLABotComment(context.Background())
+	if err != nil || !found {
+		t.Fatalf("expected the CLAassistant comment to be found, got %v, %v", found, err)
+	}
+	if fmt.Sprint(fetched) != "[9 8 7 6 5]" {
+		t.Errorf("expected the 5 most recent external PRs to be checked, got %v", fetched)
+	}
+	got := s.evidence.list()
+	if len(got) != 1 || got[0].PullRequest != 5 || got[0].Provider != ProviderCLAAssistant || got[0].URL != "https://cla-assistant.io/o/r?pullRequest=5" {
+		t.Errorf("unexpected evidence %+v", got)
+	}
+}
+
+func TestClaBot(t *testing.T) {
+	tests := []struct {
+		login    string
+		provider string
+		ok       bool
+	}{
+		{"CLAassistant", ProviderCLAAssistant, true},
+		{"google-cla[bot]", ProviderGoogle, true},
+		{"microsoft-github-policy-service[bot]", ProviderMicrosoft, true},
+		{"github-actions[bot]", "", true},
+		{"dependabot[bot]", "", false},
+	}
+	for _, tt := range tests {
+		if provider, ok := claBot(tt.login); provider != tt.provider || ok != tt.ok {
+			t.Errorf("claBot(%q) = %q, %v, wanted %q, %v", tt.login, provider, ok, tt.provider, tt.ok)
+		}
+	}
+}
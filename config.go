@@ -0,0 +1,558 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Config adjusts the built-in heuristics, so an organization can add its own phrases, owners and checks without a fork.
+// It's usually loaded from a TOML file with LoadConfig:
+//
+//	[owners]
+//	add = ["acme"]
+//	remove = ["google"]
+//
+//	[status_contexts]
+//	add = ["legal/cla"]
+//
+//	[labels]
+//	add = ["legal: signed"]
+//	remove = ["cla: no"]
+//
+//	[actions]
+//	add = ["acme/cla-check"]
+//
+//	[bots]
+//	add = ["acme-cla-bot"]
+//	remove = ["github-actions"]
+//
+//	[checks]
+//	disable = ["readme"]
+//
+//	[matchers]
+//	remove = ["cla-acronym"]
+//
+//	[[matchers.cla]]
+//	name = "contribution-agreement"
+//	phrase = "contribution agreement"
+//
+//	[[matchers.no_cla]]
+//	name = "no-agreement"
+//	pattern = '(?i)\bno contribution agreement\b'
+type Config struct {
+	// AddOwners are owners known to require a CLA, besides the built-in ones
+	AddOwners []string
+	// RemoveOwners are built-in known owners that shouldn't count
+	RemoveOwners []string
+	// AddStatusContexts are commit status contexts and check run names that show a CLA,
+	// besides Options.StatusContexts or DefaultStatusContexts
+	AddStatusContexts []string
+	// RemoveStatusContexts are status contexts that shouldn't count, matched case-insensitively
+	RemoveStatusContexts []string
+	// AddLabels are PR labels that show a CLA, matched case-insensitively, besides the built-in ones like "cla: yes"
+	AddLabels []string
+	// RemoveLabels are labels matching the built-in pattern that shouldn't count, like "cla: no"
+	RemoveLabels []string
+	// AddActions are GitHub Actions whose use in a workflow shows a CLA check, like "acme/cla-check"
+	AddActions []string
+	// RemoveActions are built-in CLA actions that shouldn't count, like "cla-assistant/github-action"
+	RemoveActions []string
+	// AddBots are the logins of bots that comment on PRs asking for a CLA, besides the built-in ones
+	AddBots []string
+	// RemoveBots are built-in CLA bots whose comments shouldn't count, like "github-actions"
+	RemoveBots []string
+	// Disable are the names of detectors not to run, like "readme"
+	Disable []string
+	// CLAMatchers find references to a CLA, besides the registered languages' phrases
+	CLAMatchers []Matcher
+	// NoCLAMatchers find statements that contributors don't need to sign a CLA, besides the registered languages' phrases
+	NoCLAMatchers []Matcher
+	// RemoveMatchers are the names of registered languages' matchers not to use, like "cla-acronym"
+	RemoveMatchers []string
+
+	// actions caches actionMatchers, which compiles a pattern for each action
+	actions     matcherSet
+	actionsOnce sync.Once
+}
+
+// ConfigError is a problem with a config file, pointing at the key or line it's on
+type ConfigError struct {
+	// Path is the config file, if it was loaded from one
+	Path string
+	// Line is the 1-based line the problem is on, or 0 if it isn't on a particular line
+	Line int
+	// Key is the dotted path of the offending key, like "matchers.cla[0].pattern", if the problem is with a key
+	Key string
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	var at []string
+	switch {
+	case e.Path != "" && e.Line != 0:
+		at = append(at, fmt.Sprintf("%s:%d", e.Path, e.Line))
+	case e.Path != "":
+		at = append(at, e.Path)
+	case e.Line != 0:
+		at = append(at, fmt.Sprintf("line %d", e.Line))
+	}
+	if e.Key != "" {
+		at = append(at, e.Key)
+	}
+	at = append(at, e.Err.Error())
+	return strings.Join(at, ": ")
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// LoadConfig reads and validates the TOML config file at path
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ParseConfig(data)
+	if ce, ok := err.(*ConfigError); ok {
+		ce.Path = path
+	}
+	return c, err
+}
+
+// ParseConfig parses and validates a TOML config, returning a *ConfigError naming the offending key if it's invalid.
+// Detectors are validated against those registered when it's called, so custom detectors should be registered first.
+func ParseConfig(data []byte) (*Config, error) {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil, syntaxError(err)
+	}
+	c := new(Config)
+	// lines records where each array is, so validate can point at its items
+	lines := make(map[string]int)
+	lists := map[string]*[]string{
+		"owners.add":             &c.AddOwners,
+		"owners.remove":          &c.RemoveOwners,
+		"status_contexts.add":    &c.AddStatusContexts,
+		"status_contexts.remove": &c.RemoveStatusContexts,
+		"labels.add":             &c.AddLabels,
+		"labels.remove":          &c.RemoveLabels,
+		"actions.add":            &c.AddActions,
+		"actions.remove":         &c.RemoveActions,
+		"bots.add":               &c.AddBots,
+		"bots.remove":            &c.RemoveBots,
+		"checks.disable":         &c.Disable,
+		"matchers.remove":        &c.RemoveMatchers,
+	}
+	matchers := map[string]*[]Matcher{
+		"matchers.cla":    &c.CLAMatchers,
+		"matchers.no_cla": &c.NoCLAMatchers,
+	}
+
+	var walk func(t *toml.Tree, prefix string) error
+	walk = func(t *toml.Tree, prefix string) error {
+		for _, k := range sortedTreeKeys(t) {
+			key, v, line := prefix+k, t.Get(k), t.GetPosition(k).Line
+			fail := func(format string, args ...interface{}) error {
+				return &ConfigError{Line: line, Key: key, Err: fmt.Errorf(format, args...)}
+			}
+			switch {
+			case contains(configTables, key):
+				sub, ok := v.(*toml.Tree)
+				if !ok {
+					return fail("expected a table, got %s", tomlType(v))
+				}
+				if err := walk(sub, key+"."); err != nil {
+					return err
+				}
+			case lists[key] != nil:
+				items, ok := v.([]interface{})
+				if !ok {
+					return fail("expected an array of strings, got %s", tomlType(v))
+				}
+				for i, item := range items {
+					str, ok := item.(string)
+					if !ok {
+						return &ConfigError{Line: line, Key: fmt.Sprintf("%s[%d]", key, i), Err: fmt.Errorf("expected a string, got %s", tomlType(item))}
+					}
+					*lists[key] = append(*lists[key], str)
+				}
+				lines[key] = line
+			case matchers[key] != nil:
+				tables, ok := v.([]*toml.Tree)
+				if !ok {
+					return fail("expected an array of tables, like [[%s]], got %s", key, tomlType(v))
+				}
+				for i, table := range tables {
+					m, err := configMatcher(fmt.Sprintf("%s[%d]", key, i), table)
+					if err != nil {
+						return err
+					}
+					*matchers[key] = append(*matchers[key], m)
+				}
+			default:
+				return fail("unknown key")
+			}
+		}
+		return nil
+	}
+	if err := walk(tree, ""); err != nil {
+		return nil, err
+	}
+
+	if err := c.validate(tree, lines); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// configTables are the tables a config file can have
+var configTables = []string{"owners", "status_contexts", "labels", "actions", "bots", "checks", "matchers"}
+
+// tomlError matches the position go-toml puts at the start of its errors, like "(3, 1): unterminated array"
+var tomlError = regexp.MustCompile(`^\((\d+), \d+\): (.*)$`)
+
+// tomlDuplicate matches go-toml's error for a key that's defined twice, which names the key
+var tomlDuplicate = regexp.MustCompile(`^The following key was defined twice: (.+)$`)
+
+// syntaxError converts an error from go-toml into a *ConfigError on the line it reports
+func syntaxError(err error) error {
+	m := tomlError.FindStringSubmatch(err.Error())
+	if m == nil {
+		return &ConfigError{Err: err}
+	}
+	line, _ := strconv.Atoi(m[1])
+	if d := tomlDuplicate.FindStringSubmatch(m[2]); d != nil {
+		return &ConfigError{Line: line, Key: d[1], Err: errors.New("already defined")}
+	}
+	return &ConfigError{Line: line, Err: errors.New(m[2])}
+}
+
+// sortedTreeKeys returns t's keys in the order they're defined in the file, so the first problem in it is reported
+func sortedTreeKeys(t *toml.Tree) []string {
+	keys := t.Keys()
+	sort.Slice(keys, func(i, j int) bool {
+		pi, pj := t.GetPosition(keys[i]), t.GetPosition(keys[j])
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Col < pj.Col
+	})
+	return keys
+}
+
+// tomlType describes the type of a value decoded by go-toml, for errors
+func tomlType(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case int64, uint64:
+		return "an integer"
+	case float64:
+		return "a float"
+	case []interface{}:
+		return "an array"
+	case *toml.Tree:
+		return "a table"
+	case []*toml.Tree:
+		return "an array of tables"
+	}
+	return "a date or time"
+}
+
+// configMatcher builds the matcher defined by the table at key, like "matchers.cla[0]"
+func configMatcher(key string, t *toml.Tree) (Matcher, error) {
+	line := t.Position().Line
+	strs := make(map[string]string)
+	for _, k := range sortedTreeKeys(t) {
+		v := t.Get(k)
+		if k != "name" && k != "phrase" && k != "pattern" {
+			return Matcher{}, &ConfigError{Line: t.GetPosition(k).Line, Key: key + "." + k, Err: fmt.Errorf("unknown key, expected name, phrase or pattern")}
+		}
+		str, ok := v.(string)
+		if !ok {
+			return Matcher{}, &ConfigError{Line: t.GetPosition(k).Line, Key: key + "." + k, Err: fmt.Errorf("expected a string, got %s", tomlType(v))}
+		}
+		strs[k] = str
+	}
+	name := strs["name"]
+	if name == "" {
+		return Matcher{}, &ConfigError{Line: line, Key: key + ".name", Err: fmt.Errorf("missing matcher name")}
+	}
+	phrase, hasPhrase := strs["phrase"]
+	pattern, hasPattern := strs["pattern"]
+	switch {
+	case hasPhrase == hasPattern:
+		return Matcher{}, &ConfigError{Line: line, Key: key, Err: fmt.Errorf("expected either a phrase or a pattern")}
+	case hasPhrase:
+		if strings.TrimSpace(phrase) == "" {
+			return Matcher{}, &ConfigError{Line: t.GetPosition("phrase").Line, Key: key + ".phrase", Err: fmt.Errorf("empty phrase")}
+		}
+		return Phrase(name, phrase), nil
+	}
+	m, err := NewMatcher(name, pattern)
+	if err != nil {
+		return Matcher{}, &ConfigError{Line: t.GetPosition("pattern").Line, Key: key + ".pattern", Err: err}
+	}
+	return m, nil
+}
+
+// validate checks that c's names refer to registered detectors and matchers.
+// lines are the lines of c's arrays, keyed like "checks.disable".
+func (c *Config) validate(tree *toml.Tree, lines map[string]int) error {
+	detectors := make(map[string]bool)
+	for _, det := range Detectors() {
+		detectors[det.Name()] = true
+	}
+	for i, name := range c.Disable {
+		if !detectors[name] {
+			return configListError(lines, "checks.disable", i, fmt.Errorf("unknown check %q", name))
+		}
+	}
+
+	matchers := map[string]bool{claCheckbox.Name: true}
+	for _, l := range Languages() {
+		for _, m := range append(append([]Matcher(nil), l.CLA...), l.NoCLA...) {
+			matchers[m.Name] = true
+		}
+	}
+	for i, name := range c.RemoveMatchers {
+		if !matchers[name] {
+			return configListError(lines, "matchers.remove", i, fmt.Errorf("unknown matcher %q", name))
+		}
+	}
+	for i, m := range append(append([]Matcher(nil), c.CLAMatchers...), c.NoCLAMatchers...) {
+		if matchers[m.Name] {
+			table, j := "matchers.cla", i
+			if i >= len(c.CLAMatchers) {
+				table, j = "matchers.no_cla", i-len(c.CLAMatchers)
+			}
+			t := tree.Get(table).([]*toml.Tree)[j]
+			return &ConfigError{Line: t.GetPosition("name").Line, Key: fmt.Sprintf("%s[%d].name", table, j), Err: fmt.Errorf("matcher %q is already defined", m.Name)}
+		}
+		matchers[m.Name] = true
+	}
+
+	for i, label := range c.RemoveLabels {
+		if !prLabelMatcher.Match([]byte(label)) {
+			return configListError(lines, "labels.remove", i, fmt.Errorf("%q isn't a built-in label, they're like \"cla: yes\"", label))
+		}
+	}
+	for i, action := range c.AddActions {
+		if !isActionName(action) {
+			return configListError(lines, "actions.add", i, fmt.Errorf("expected an action like \"owner/name\", got %q", action))
+		}
+	}
+	for i, action := range c.RemoveActions {
+		if _, ok := claActions[strings.ToLower(action)]; !ok {
+			return configListError(lines, "actions.remove", i, fmt.Errorf("unknown action %q", action))
+		}
+	}
+	for i, login := range c.RemoveBots {
+		if _, ok := claBot(login); !ok {
+			return configListError(lines, "bots.remove", i, fmt.Errorf("unknown bot %q", login))
+		}
+	}
+
+	for _, list := range []struct {
+		key    string
+		values []string
+	}{
+		{"owners.add", c.AddOwners},
+		{"owners.remove", c.RemoveOwners},
+		{"status_contexts.add", c.AddStatusContexts},
+		{"status_contexts.remove", c.RemoveStatusContexts},
+		{"labels.add", c.AddLabels},
+		{"bots.add", c.AddBots},
+	} {
+		for i, v := range list.values {
+			if strings.TrimSpace(v) == "" {
+				return configListError(lines, list.key, i, fmt.Errorf("empty value"))
+			}
+		}
+	}
+	return nil
+}
+
+// configListError points at item i of the array at key
+func configListError(lines map[string]int, key string, i int, err error) error {
+	return &ConfigError{Line: lines[key], Key: fmt.Sprintf("%s[%d]", key, i), Err: err}
+}
+
+// knownOwners returns the built-in known owners, adjusted by c
+func (c *Config) knownOwners() []string {
+	if c == nil {
+		return knownOwners
+	}
+	var owners []string
+	for _, o := range append(append([]string(nil), knownOwners...), c.AddOwners...) {
+		if !containsFold(c.RemoveOwners, o) {
+			owners = append(owners, o)
+		}
+	}
+	return owners
+}
+
+// statusContexts returns contexts, or DefaultStatusContexts if it's nil, adjusted by c
+func (c *Config) statusContexts(contexts []string) []string {
+	if c == nil || len(c.AddStatusContexts) == 0 && len(c.RemoveStatusContexts) == 0 {
+		return contexts
+	}
+	if contexts == nil {
+		contexts = DefaultStatusContexts
+	}
+	adjusted := []string{}
+	for _, ctx := range append(append([]string(nil), contexts...), c.AddStatusContexts...) {
+		if !containsFold(c.RemoveStatusContexts, ctx) {
+			adjusted = append(adjusted, ctx)
+		}
+	}
+	return adjusted
+}
+
+// detectors returns the detectors c doesn't disable
+func (c *Config) detectors(all []Detector) []Detector {
+	if c == nil || len(c.Disable) == 0 {
+		return all
+	}
+	var enabled []Detector
+	for _, det := range all {
+		if !contains(c.Disable, det.Name()) {
+			enabled = append(enabled, det)
+		}
+	}
+	return enabled
+}
+
+// claMatchers is the package's claMatchers, adjusted by c
+func (c *Config) claMatchers() matcherSet {
+	if c == nil {
+		return claMatchers()
+	}
+	var ms matcherSet
+	for _, l := range Languages() {
+		ms = append(ms, c.keep(l.CLA)...)
+	}
+	ms = append(ms, c.CLAMatchers...)
+	return append(ms, negate(c.noCLAMatchers())...)
+}
+
+// noCLAMatchers is the package's noCLAMatchers, adjusted by c
+func (c *Config) noCLAMatchers() matcherSet {
+	if c == nil {
+		return noCLAMatchers()
+	}
+	var ms matcherSet
+	for _, l := range Languages() {
+		ms = append(ms, c.keep(l.NoCLA)...)
+	}
+	return append(ms, c.NoCLAMatchers...)
+}
+
+// templateMatchers returns the matchers for CLA checkboxes, then any other CLA reference, adjusted by c
+func (c *Config) templateMatchers() matcherSet {
+	ms := matcherSet{claCheckbox}
+	if c != nil && contains(c.RemoveMatchers, claCheckbox.Name) {
+		ms = nil
+	}
+	return append(ms, c.claMatchers()...)
+}
+
+// labelMatcher returns the name of the matcher that label matches, adjusted by c, or "" if it doesn't show a CLA
+func (c *Config) labelMatcher(label string) string {
+	if c != nil {
+		if containsFold(c.RemoveLabels, label) {
+			return ""
+		}
+		if containsFold(c.AddLabels, label) {
+			return configLabel
+		}
+	}
+	if prLabelMatcher.Match([]byte(label)) {
+		return prLabelMatcher.Name
+	}
+	return ""
+}
+
+// actionMatchers returns matchers for workflow steps using the built-in CLA actions, adjusted by c.
+// Each is named after its action.
+func (c *Config) actionMatchers() matcherSet {
+	if c == nil {
+		return actionMatcher
+	}
+	c.actionsOnce.Do(func() {
+		for _, m := range actionMatcher {
+			if !containsFold(c.RemoveActions, m.Name) {
+				c.actions = append(c.actions, m)
+			}
+		}
+		for _, action := range c.AddActions {
+			c.actions = append(c.actions, usesAction(action))
+		}
+	})
+	return c.actions
+}
+
+// claBot is the package's claBot, adjusted by c
+func (c *Config) claBot(login string) (string, bool) {
+	if c != nil {
+		login = strings.TrimSuffix(login, "[bot]")
+		for _, bot := range c.RemoveBots {
+			if strings.EqualFold(strings.TrimSuffix(bot, "[bot]"), login) {
+				return "", false
+			}
+		}
+		for _, bot := range c.AddBots {
+			if strings.EqualFold(strings.TrimSuffix(bot, "[bot]"), login) {
+				return "", true
+			}
+		}
+	}
+	return claBot(login)
+}
+
+// keep returns the matchers c doesn't remove
+func (c *Config) keep(ms []Matcher) []Matcher {
+	var kept []Matcher
+	for _, m := range ms {
+		if !contains(c.RemoveMatchers, m.Name) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import "testing"
+
+func TestConfigLabelMatcher(t *testing.T) {
+	c := &Config{AddLabels: []string{"legal: signed"}, RemoveLabels: []string{"cla: no"}}
+	tests := map[string]string{
+		"cla: yes":      prLabelMatcher.Name,
+		"CLA: No":       "",
+		"Legal: Signed": configLabel,
+		"legal":         "",
+	}
+	for label, want := range tests {
+		if got := c.labelMatcher(label); got != want {
+			t.Errorf("%q: got %q, wanted %q", label, got, want)
+		}
+	}
+	var none *Config
+	if got := none.labelMatcher("cla: no"); got != prLabelMatcher.Name {
+		t.Errorf("expected a nil config to use the built-in labels, got %q", got)
+	}
+}
+
+func TestConfigClaBot(t *testing.T) {
+	c := &Config{AddBots: []string{"acme-cla-bot"}, RemoveBots: []string{"github-actions"}}
+	tests := []struct {
+		login    string
+		provider string
+		ok       bool
+	}{
+		{"acme-cla-bot[bot]", "", true},
+		{"github-actions[bot]", "", false},
+		{"googlebot", ProviderGoogle, true},
+		{"octocat", "", false},
+	}
+	for _, tt := range tests {
+		if provider, ok := c.claBot(tt.login); provider != tt.provider || ok != tt.ok {
+			t.Errorf("claBot(%q) = %q, %v, wanted %q, %v", tt.login, provider, ok, tt.provider, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+const testConfig = `# adjustments for acme
+[owners]
+add = ["acme"]
+remove = ["progressive-insurance"]
+
+[status_contexts]
+add = [
+  "legal/cla", # our own bot
+]
+
+[checks]
+disable = ["readme"]
+
+[matchers]
+remove = ["cla-acronym"]
+
+[[matchers.cla]]
+name = "contribution-agreement"
+phrase = "contribution agreement"
+
+[[matchers.no_cla]]
+name = "no-agreement"
+pattern = '(?i)\bno contribution agreement\b'
+`
+
+func TestParseConfig(t *testing.T) {
+	c, err := needcla.ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(c.AddOwners, ",") != "acme" || strings.Join(c.RemoveOwners, ",") != "progressive-insurance" {
+		t.Errorf("unexpected owners %v and %v", c.AddOwners, c.RemoveOwners)
+	}
+	if strings.Join(c.AddStatusContexts, ",") != "legal/cla" {
+		t.Errorf("unexpected status contexts %v", c.AddStatusContexts)
+	}
+	if strings.Join(c.Disable, ",") != "readme" || strings.Join(c.RemoveMatchers, ",") != "cla-acronym" {
+		t.Errorf("unexpected disabled checks %v and removed matchers %v", c.Disable, c.RemoveMatchers)
+	}
+	if len(c.CLAMatchers) != 1 || c.CLAMatchers[0].Name != "contribution-agreement" ||
+		!c.CLAMatchers[0].Match([]byte("Sign the Contribution\nAgreement first.")) {
+		t.Errorf("unexpected CLA matchers %v", c.CLAMatchers)
+	}
+	if len(c.NoCLAMatchers) != 1 || c.NoCLAMatchers[0].Name != "no-agreement" ||
+		!c.NoCLAMatchers[0].Match([]byte("There's no contribution agreement.")) {
+		t.Errorf("unexpected no-CLA matchers %v", c.NoCLAMatchers)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		line   int
+		key    string
+	}{
+		{"unknown key", "[owners]\nadd = [\"acme\"]\nignore = [\"google\"]\n", 3, "owners.ignore"},
+		{"unknown table", "[reviewers]\n", 1, "reviewers"},
+		{"not a string", "[owners]\nadd = [\"acme\", 1]\n", 2, "owners.add[1]"},
+		{"not a table", "checks = [\"readme\"]\n", 1, "checks"},
+		{"not an array", "[checks]\ndisable = \"readme\"\n", 2, "checks.disable"},
+		{"unknown check", "[checks]\ndisable = [\"readme\", \"reedme\"]\n", 2, "checks.disable[1]"},
+		{"unknown matcher", "[matchers]\nremove = [\"cla-acronim\"]\n", 2, "matchers.remove[0]"},
+		{"empty owner", "[owners]\nadd = [\"acme\", \" \"]\n", 2, "owners.add[1]"},
+		{"duplicate key", "[owners]\nadd = [\"acme\"]\nadd = [\"initech\"]\n", 3, "owners.add"},
+		{"unclosed string", "[owners]\nadd = [\"acme]\n", 2, ""},
+		{"unclosed array", "[owners]\nadd = [\"acme\",\n\n[checks]\n", 4, ""},
+		{"bad pattern", "[[matchers.cla]]\nname = \"broken\"\npattern = \"(\"\n", 3, "matchers.cla[0].pattern"},
+		{"unknown matcher key", "[[matchers.cla]]\nname = \"x\"\nregex = \"x\"\n", 3, "matchers.cla[0].regex"},
+		{"missing name", "[[matchers.no_cla]]\nphrase = \"no agreement\"\n", 1, "matchers.no_cla[0].name"},
+		{"phrase and pattern", "[[matchers.cla]]\nname = \"x\"\nphrase = \"x\"\npattern = \"x\"\n", 1, "matchers.cla[0]"},
+		{"builtin name", "[[matchers.cla]]\nname = \"cla-phrase\"\nphrase = \"x\"\n", 2, "matchers.cla[0].name"},
+		{"no equals", "[owners]\nacme\n", 2, ""},
+		{"not a built-in label", "[labels]\nremove = [\"legal: signed\"]\n", 2, "labels.remove[0]"},
+		{"not an action", "[actions]\nadd = [\"cla-check\"]\n", 2, "actions.add[0]"},
+		{"unknown action", "[actions]\nremove = [\"acme/cla-check\"]\n", 2, "actions.remove[0]"},
+		{"unknown bot", "[bots]\nremove = [\"acme-bot\"]\n", 2, "bots.remove[0]"},
+		{"matcher not a table", "[matchers]\ncla = \"x\"\n", 2, "matchers.cla"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := needcla.ParseConfig([]byte(test.config))
+			var ce *needcla.ConfigError
+			if !errors.As(err, &ce) {
+				t.Fatalf("expected a *ConfigError, got %v", err)
+			}
+			if ce.Line != test.line || ce.Key != test.key {
+				t.Errorf("expected line %d key %q, got %v", test.line, test.key, err)
+			}
+		})
+	}
+}
+
+func TestDetailSourceWithConfigActions(t *testing.T) {
+	c, err := needcla.ParseConfig([]byte("[actions]\nadd = [\"acme/cla-check\"]\nremove = [\"cla-assistant/github-action\"]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]bool{
+		"steps:\n  - uses: acme/cla-check@v1\n":                       true,
+		"steps:\n  - uses: 'acme/cla-check'\n":                        true,
+		"steps:\n  - uses: acme/cla-checker@v1\n":                     false,
+		"steps:\n  - uses: cla-assistant/github-action@v2.1.3-beta\n": false,
+	}
+	for workflow, want := range tests {
+		src := needcla.NewFSSource(fstest.MapFS{".github/workflows/cla.yml": {Data: []byte(workflow)}})
+		d, err := needcla.DetailSourceWithOptions(context.Background(), src, "", "", needcla.Options{Config: c})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if d.Action != want {
+			t.Errorf("%q: got %v, wanted %v", workflow, d.Action, want)
+		}
+		if want && (len(d.Evidence) != 1 || d.Evidence[0].Matcher != "acme/cla-check" || d.Evidence[0].Provider != "") {
+			t.Errorf("%q: unexpected evidence %+v", workflow, d.Evidence)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "need-cla.toml")
+	if err := os.WriteFile(path, []byte("[checks]\ndisable = [\"dco\", \"nope\"]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := needcla.LoadConfig(path)
+	if err == nil || err.Error() != path+`:2: checks.disable[1]: unknown check "nope"` {
+		t.Errorf("expected the error to point at the file, line and key, got %v", err)
+	}
+}
+
+func TestDetailSourceWithConfig(t *testing.T) {
+	c, err := needcla.ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"README.md":       {Data: []byte("Contributions need a CLA.\n")},
+		"CONTRIBUTING.md": {Data: []byte("Please sign our contribution agreement before opening a PR.\n")},
+	}
+	src := needcla.NewFSSource(fsys)
+
+	d, err := needcla.DetailSource(context.Background(), src, "progressive-insurance", "need-cla")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.Known || !d.InREADME || d.InContributing {
+		t.Errorf("expected the built-in heuristics without a config, got %+v", d)
+	}
+
+	d, err = needcla.DetailSourceWithOptions(context.Background(), src, "progressive-insurance", "need-cla", needcla.Options{Config: c})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Known || d.InREADME || !d.InContributing {
+		t.Errorf("expected the config's owners, checks and matchers, got %+v", d)
+	}
+
+	d, err = needcla.DetailSourceWithOptions(context.Background(), src, "acme", "widgets", needcla.Options{Config: c})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.Known {
+		t.Errorf("expected an added owner to be known, got %+v", d)
+	}
+}
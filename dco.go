@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// dcoConfigFiles configure the DCO GitHub App, a probot app
+// https://github.com/dcoapp/app
+var dcoConfigFiles = []string{".github/dco.yml", ".github/dco.yaml"}
+
+const (
+	// dcoSample is how many recent commits are checked for Signed-off-by trailers
+	dcoSample = 20
+	// dcoMinCommits is the fewest commits that can show a sign-off policy
+	dcoMinCommits = 5
+	// dcoSignedOffRatio is the share of sampled commits that must be signed off to show a policy,
+	// leaving room for merge commits and bots
+	dcoSignedOffRatio = 0.8
+)
+
+// usesDCO looks for a Developer Certificate of Origin sign-off policy:
+// DCO app config, a DCO check in CI, CONTRIBUTING.md asking for sign-offs, or recent commits that are signed off
+func (s *Snapshot) usesDCO(ctx context.Context) (bool, error) {
+	var found bool
+	var errs []string
+	for _, path := range dcoConfigFiles {
+		e, err := s.find(ctx, path)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("* %s: %v", path, err))
+			continue
+		}
+		if e != nil {
+			s.AddEvidence(Evidence{Path: e.Path, SHA: e.SHA})
+			found = true
+		}
+	}
+
+	workflows, err := s.list(ctx, ".github/workflows")
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("* .github/workflows: %v", err))
+	}
+	for _, e := range workflows {
+		if e.Dir {
+			continue
+		}
+		content, err := s.readEntry(ctx, e)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("* %s: %v", e.Path, err))
+			continue
+		}
+		if ev := dcoActionMatcher.match(content); ev != nil {
+			ev.Path, ev.SHA, ev.Step = e.Path, e.SHA, workflowStep(content, ev.Line)
+			s.AddEvidence(*ev)
+			found = true
+		}
+	}
+
+	e, content, err := s.readDoc(ctx, contributingDoc)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("* CONTRIBUTING: %v", err))
+	}
+	if e != nil {
+		if ev := dcoMatchers.matchFile(e.Path, content); ev != nil {
+			ev.at(e)
+			s.AddEvidence(*ev)
+			found = true
+		}
+	}
+
+	signedOff, err := s.signedOffCommits(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("* recent commits: %v", err))
+	}
+	found = found || signedOff
+
+	if len(errs) != 0 && !found {
+		return false, fmt.Errorf("%d error(s) checking for a DCO:\n\t%s", len(errs), strings.Join(errs, "\n\t"))
+	}
+	return found, nil
+}
+
+// signedOffCommits reports whether most recent commits on the branch have Signed-off-by trailers.
+// It's false for snapshots whose forge can't list commits.
+func (s *Snapshot) signedOffCommits(ctx context.Context) (bool, error) {
+	lister, ok := s.forge.(CommitLister)
+	if !ok {
+		return false, nil
+	}
+	commits, err := lister.Commits(ctx, s.owner, s.repo, s.branch, dcoSample)
+	if err != nil {
+		return false, fmt.Errorf("error getting %s/%s commits: %v", s.owner, s.repo, err)
+	}
+	var signed []Commit
+	for _, c := range commits {
+		if signedOff(c.Message) {
+			signed = append(signed, c)
+		}
+	}
+	if len(commits) < dcoMinCommits || float64(len(signed)) < dcoSignedOffRatio*float64(len(commits)) {
+		return false, nil
+	}
+	s.AddEvidence(Evidence{
+		SHA:     signed[0].SHA,
+		Snippet: fmt.Sprintf("%d of the %d most recent commits are signed off", len(signed), len(commits)),
+	})
+	return true, nil
+}
+
+// signedOff reports whether a commit message's trailers, its last paragraph, include a Signed-off-by,
+// so one quoted in the body, like in a revert, doesn't count
+func signedOff(message string) bool {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	return signedOffMatcher.MatchString(paragraphs[len(paragraphs)-1])
+}
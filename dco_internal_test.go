@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// fakeCommitLister is a Forge that only lists commits
+type fakeCommitLister struct {
+	Forge
+	commits []Commit
+}
+
+func (f fakeCommitLister) Commits(ctx context.Context, owner, repo, branch string, n int) ([]Commit, error) {
+	if n < len(f.commits) {
+		return f.commits[:n], nil
+	}
+	return f.commits, nil
+}
+
+// testCommits returns total commits, the first signed of which are signed off with message
+func testCommits(total, signed int, message string) []Commit {
+	out := make([]Commit, 0, total)
+	for i := 0; i < total; i++ {
+		c := Commit{SHA: fmt.Sprintf("c%d", i), Message: fmt.Sprintf("Fix %d\n\nDetails.\n", i)}
+		if i < signed {
+			c.Message = message
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func TestSignedOffCommits(t *testing.T) {
+	const trailer = "Fix the build\n\nDetails.\n\nSigned-off-by: Dev <dev@example.com>\n"
+	tests := []struct {
+		name    string
+		commits []Commit
+		want    bool
+	}{
+		{"too few commits", testCommits(dcoMinCommits-1, dcoMinCommits-1, trailer), false},
+		{"enough commits", testCommits(dcoMinCommits, dcoMinCommits, trailer), true},
+		{"16 of 20", testCommits(20, 16, trailer), true},
+		{"15 of 20", testCommits(20, 15, trailer), false},
+		{"outside the trailer", testCommits(20, 20, "Revert \"Fix the build\"\n\nSigned-off-by: Dev <dev@example.com>\nwas wrong.\n\nFixes #1\n"), false},
+		{"mentioned in the subject", testCommits(20, 20, "Require a Signed-off-by: Dev <dev@example.com> line\n\nDetails.\n"), false},
+		{"windows line endings", testCommits(20, 20, "Fix the build\r\n\r\nSigned-off-by: Dev <dev@example.com>\r\n"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Snapshot{
+				owner:    "o",
+				repo:     "r",
+				branch:   "main",
+				forge:    fakeCommitLister{commits: tt.commits},
+				detector: DCODetector,
+				evidence: new(evidenceLog),
+			}
+			got, err := s.signedOffCommits(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, wanted %v", got, tt.want)
+			}
+			if ev := s.evidence.list(); tt.want && (len(ev) != 1 || ev[0].SHA != "c0") {
+				t.Errorf("expected evidence pointing at the newest signed off commit, got %+v", ev)
+			}
+		})
+	}
+}
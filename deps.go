@@ -0,0 +1,295 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Ecosystems of the dependencies found in manifests
+const (
+	EcosystemGo    = "go"
+	EcosystemNPM   = "npm"
+	EcosystemPyPI  = "pypi"
+	EcosystemCargo = "cargo"
+	EcosystemMaven = "maven"
+)
+
+// manifests maps the manifest files ReadDependencies looks for to their parsers
+var manifests = map[string]func([]byte) ([]Dependency, error){
+	"go.mod":           parseGoMod,
+	"package.json":     parsePackageJSON,
+	"requirements.txt": parseRequirements,
+	"Cargo.toml":       parseCargoToml,
+	"pom.xml":          parsePom,
+}
+
+// requirement matches the project name, optional extras and version specifier of a requirements.txt line
+var requirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*([^;]*)`)
+
+// Dependency is an upstream project named in a manifest
+type Dependency struct {
+	// Name is how the manifest names the dependency, like golang.org/x/oauth2 or org.slf4j:slf4j-api
+	Name string
+	// Version is the required version, if the manifest pins one
+	Version string
+	// Ecosystem is the package ecosystem the name belongs to, like EcosystemGo
+	Ecosystem string
+	// Manifest is the path of the file the dependency was found in
+	Manifest string
+	// Indirect is true for dependencies the manifest only lists for the sake of another dependency
+	Indirect bool
+}
+
+// DependencyResult is the outcome of checking the repository that hosts one or more dependencies
+type DependencyResult struct {
+	// Reference is the source repository, empty if it couldn't be resolved
+	Reference Reference
+	// Dependencies are every dependency hosted in the repository
+	Dependencies []Dependency
+	// Details are the results of checking the repository
+	Details Details
+	// Err is non-nil if the repository couldn't be resolved or checked,
+	// in which case whether it needs a CLA is unknown
+	Err error
+}
+
+// ReadDependencies reads the dependencies from every manifest it recognizes in the root of src:
+// go.mod, package.json, requirements.txt, Cargo.toml and pom.xml
+func ReadDependencies(ctx context.Context, src Source) ([]Dependency, error) {
+	entries, err := src.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	var deps []Dependency
+	for _, e := range entries {
+		parse, ok := manifests[e.Path]
+		if !ok || e.Dir {
+			continue
+		}
+		content, err := src.ReadFile(ctx, e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", e.Path, err)
+		}
+		found, err := parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", e.Path, err)
+		}
+		for _, d := range found {
+			d.Manifest = e.Path
+			deps = append(deps, d)
+		}
+	}
+	return deps, nil
+}
+
+// DetailDependencies resolves deps to their source repositories and checks each repository once with detail,
+// returning results in the order the repositories were first seen.
+// Dependencies that can't be resolved each get their own result with Err set.
+func DetailDependencies(ctx context.Context, r *Resolver, deps []Dependency, detail func(context.Context, Reference) (Details, error)) []DependencyResult {
+	var results []*DependencyResult
+	byRef := make(map[Reference]*DependencyResult)
+	for _, dep := range deps {
+		ref, err := r.Resolve(ctx, dep)
+		if err != nil {
+			results = append(results, &DependencyResult{Dependencies: []Dependency{dep}, Err: err})
+			continue
+		}
+		if result, ok := byRef[ref]; ok {
+			result.Dependencies = append(result.Dependencies, dep)
+			continue
+		}
+		result := &DependencyResult{Reference: ref, Dependencies: []Dependency{dep}}
+		byRef[ref] = result
+		results = append(results, result)
+	}
+
+	out := make([]DependencyResult, 0, len(results))
+	for _, result := range results {
+		if result.Err == nil {
+			result.Details, result.Err = detail(ctx, result.Reference)
+		}
+		out = append(out, *result)
+	}
+	return out
+}
+
+// parseGoMod reads the require directives of a go.mod file
+func parseGoMod(content []byte) ([]Dependency, error) {
+	var deps []Dependency
+	var inRequire bool
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		indirect := strings.HasSuffix(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case line == "require (":
+			inRequire = true
+			continue
+		case inRequire && line == ")":
+			inRequire = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require"))
+		case !inRequire:
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Name:      strings.Trim(fields[0], `"`),
+			Version:   fields[1],
+			Ecosystem: EcosystemGo,
+			Indirect:  indirect,
+		})
+	}
+	return deps, scanner.Err()
+}
+
+// parsePackageJSON reads the dependencies and devDependencies of a package.json file
+func parsePackageJSON(content []byte) ([]Dependency, error) {
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, err
+	}
+	var deps []Dependency
+	for _, group := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+		for _, name := range sortedKeys(group) {
+			deps = append(deps, Dependency{Name: name, Version: group[name], Ecosystem: EcosystemNPM})
+		}
+	}
+	return deps, nil
+}
+
+// parseRequirements reads a pip requirements.txt file
+func parseRequirements(content []byte) ([]Dependency, error) {
+	var deps []Dependency
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		// direct references, like git+https://github.com/owner/repo.git@v1#egg=name or name @ https://...
+		if strings.Contains(line, "://") {
+			if i := strings.Index(line, " @ "); i >= 0 {
+				line = strings.TrimSpace(line[i+3:])
+			}
+			url := strings.TrimPrefix(line, "git+")
+			if i := strings.Index(url, "#"); i >= 0 {
+				url = url[:i]
+			}
+			if i := strings.LastIndex(url, "@"); i > strings.LastIndex(url, "/") {
+				url = url[:i]
+			}
+			deps = append(deps, Dependency{Name: url, Ecosystem: EcosystemPyPI})
+			continue
+		}
+		m := requirement.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		deps = append(deps, Dependency{Name: m[1], Version: strings.TrimSpace(m[2]), Ecosystem: EcosystemPyPI})
+	}
+	return deps, scanner.Err()
+}
+
+// parseCargoToml reads the dependency tables of a Cargo.toml file, including platform-specific ones under [target]
+func parseCargoToml(content []byte) ([]Dependency, error) {
+	tree, err := toml.LoadBytes(content)
+	if err != nil {
+		return nil, err
+	}
+	tables := []*toml.Tree{tree}
+	if targets, ok := tree.Get("target").(*toml.Tree); ok {
+		for _, target := range targets.Keys() {
+			if t, ok := targets.Get(target).(*toml.Tree); ok {
+				tables = append(tables, t)
+			}
+		}
+	}
+	var deps []Dependency
+	for _, table := range tables {
+		for _, kind := range []string{"dependencies", "dev-dependencies", "build-dependencies"} {
+			group, ok := table.Get(kind).(*toml.Tree)
+			if !ok {
+				continue
+			}
+			names := group.Keys()
+			sort.Strings(names)
+			for _, name := range names {
+				dep := Dependency{Name: name, Ecosystem: EcosystemCargo}
+				switch v := group.Get(name).(type) {
+				case string:
+					dep.Version = v
+				case *toml.Tree:
+					// renamed dependencies name the real crate with package = "..."
+					if p, ok := v.Get("package").(string); ok {
+						dep.Name = p
+					}
+					dep.Version, _ = v.Get("version").(string)
+				}
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// parsePom reads the dependencies of a Maven pom.xml file
+func parsePom(content []byte) ([]Dependency, error) {
+	var pom struct {
+		Dependencies []struct {
+			GroupID    string `xml:"groupId"`
+			ArtifactID string `xml:"artifactId"`
+			Version    string `xml:"version"`
+		} `xml:"dependencies>dependency"`
+	}
+	if err := xml.Unmarshal(content, &pom); err != nil {
+		return nil, err
+	}
+	deps := make([]Dependency, 0, len(pom.Dependencies))
+	for _, d := range pom.Dependencies {
+		deps = append(deps, Dependency{
+			Name:      strings.TrimSpace(d.GroupID) + ":" + strings.TrimSpace(d.ArtifactID),
+			Version:   strings.TrimSpace(d.Version),
+			Ecosystem: EcosystemMaven,
+		})
+	}
+	return deps, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseManifests(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func([]byte) ([]Dependency, error)
+		content string
+		want    []Dependency
+	}{
+		{
+			name:  "go.mod",
+			parse: parseGoMod,
+			content: `module example.com/m
+
+go 1.18
+
+require github.com/google/go-github/v43 v43.0.0
+
+require (
+	github.com/peterbourgon/ff/v3 v3.1.2
+	golang.org/x/oauth2 v0.0.0-20220309155454-6242fa91716a // indirect
+)
+`,
+			want: []Dependency{
+				{Name: "github.com/google/go-github/v43", Version: "v43.0.0", Ecosystem: EcosystemGo},
+				{Name: "github.com/peterbourgon/ff/v3", Version: "v3.1.2", Ecosystem: EcosystemGo},
+				{Name: "golang.org/x/oauth2", Version: "v0.0.0-20220309155454-6242fa91716a", Ecosystem: EcosystemGo, Indirect: true},
+			},
+		},
+		{
+			name:    "package.json",
+			parse:   parsePackageJSON,
+			content: `{"dependencies": {"react": "^18.0.0", "@babel/core": "7.17.0"}, "devDependencies": {"jest": "27"}}`,
+			want: []Dependency{
+				{Name: "@babel/core", Version: "7.17.0", Ecosystem: EcosystemNPM},
+				{Name: "react", Version: "^18.0.0", Ecosystem: EcosystemNPM},
+				{Name: "jest", Version: "27", Ecosystem: EcosystemNPM},
+			},
+		},
+		{
+			name:  "requirements.txt",
+			parse: parseRequirements,
+			content: `# tools
+-r dev.txt
+requests[security]>=2.8.1 ; python_version < "3.8"
+Django==4.0  # pinned
+git+https://github.com/owner/repo.git@v1.0#egg=repo
+pkg @ git+ssh://git@github.com/owner/pkg.git
+`,
+			want: []Dependency{
+				{Name: "requests", Version: ">=2.8.1", Ecosystem: EcosystemPyPI},
+				{Name: "Django", Version: "==4.0", Ecosystem: EcosystemPyPI},
+				{Name: "https://github.com/owner/repo.git", Ecosystem: EcosystemPyPI},
+				{Name: "ssh://git@github.com/owner/pkg.git", Ecosystem: EcosystemPyPI},
+			},
+		},
+		{
+			name:  "Cargo.toml",
+			parse: parseCargoToml,
+			content: `[package]
+name = "example"
+version = "0.1.0"
+
+[dependencies]
+serde = "1.0"
+json = { package = "serde_json", version = "1.0" }
+
+[dependencies.tokio]
+version = "1"
+features = ["full"] # not a dependency
+
+[dev-dependencies]
+rand = { version = "0.8" }
+
+[target.'cfg(unix)'.dependencies]
+libc = "0.2"
+`,
+			want: []Dependency{
+				{Name: "serde_json", Version: "1.0", Ecosystem: EcosystemCargo},
+				{Name: "serde", Version: "1.0", Ecosystem: EcosystemCargo},
+				{Name: "tokio", Version: "1", Ecosystem: EcosystemCargo},
+				{Name: "rand", Version: "0.8", Ecosystem: EcosystemCargo},
+				{Name: "libc", Version: "0.2", Ecosystem: EcosystemCargo},
+			},
+		},
+		{
+			name:  "pom.xml",
+			parse: parsePom,
+			content: `<project>
+  <dependencies>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version>1.7.36</version>
+    </dependency>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+    </dependency>
+  </dependencies>
+</project>`,
+			want: []Dependency{
+				{Name: "org.slf4j:slf4j-api", Version: "1.7.36", Ecosystem: EcosystemMaven},
+				{Name: "junit:junit", Ecosystem: EcosystemMaven},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.parse([]byte(tt.content))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, wanted %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -7,24 +7,117 @@ the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/
 
 package needcla
 
-// Details contains the results for CLA requirement using various hueristics
+// Details contains the results for CLA requirement using various hueristics.
+// The original checks have fields of their own, and every other detector's result is in Results; Result reads either.
+// In JSON, each result is keyed by its detector's name.
 type Details struct {
 	// Known is true if the owner of a repo is a known CLA requiror
-	Known bool
+	Known bool `json:"known-owner"`
 	// Tag is true if a sample of PRs in the repo use a 'cla: yes' and/or 'cla: no' label
-	Tag bool
+	Tag bool `json:"pr-label"`
 	// BotFile is true if a .clabot config file is present in root
-	BotFile bool
-	// InContributing is true if the repo's CONTRIBUTING.md exists and refrences the CLA string matchers
-	InContributing bool
-	// InREADME is true if the repo's README.md exists and references the CLA string matchers
-	InREADME bool
-	// Action is true if a .github/workflow file has a 'uses: cla-assistant/github-action' line
-	Action bool
+	BotFile bool `json:"clabot-file"`
+	// InContributing is true if the repo's CONTRIBUTING exists and refrences the CLA string matchers.
+	// Like on GitHub, it can be in the root, .github or docs, with any extension.
+	InContributing bool `json:"contributing"`
+	// InREADME is true if the repo's README exists and references the CLA string matchers.
+	// Like on GitHub, it can be in the root, .github or docs, with any extension.
+	InREADME bool `json:"readme"`
+	// Action is true if a .github/workflow file has a 'uses: cla-assistant/github-action' line,
+	// or .gitlab-ci.yml has a CLA job
+	Action bool `json:"cla-assistant-action"`
+	// Results holds the results of the detectors without a field above, built in or registered, keyed by detector name,
+	// like Results[NoCLADetector]
+	Results map[string]bool `json:"results,omitempty"`
+	// Docs are the paths of the community documents that were checked, keyed by name like "CONTRIBUTING"
+	Docs map[string]string `json:"docs,omitempty"`
+	// Unknown lists the detectors that couldn't tell either way, in detector registration order
+	Unknown []string `json:"unknown,omitempty"`
+	// Provider is the CLA service the evidence points to, like ProviderEasyCLA, or "" if none was recognized
+	Provider string `json:"provider,omitempty"`
+	// SigningURLs are the links to CLA signing pages found in the repo, in evidence order
+	SigningURLs []string `json:"signing_urls,omitempty"`
+	// Evidence is what made each detector that found a CLA find it, in detector registration order
+	Evidence []Evidence `json:"evidence,omitempty"`
+	// Branch is the branch that was checked
+	Branch string `json:"branch,omitempty"`
+	// Commit is the SHA of the commit that was checked, if the forge reports it
+	Commit string `json:"commit,omitempty"`
 }
 
+// Required reports whether DefaultScoring is more than NotRequiredAt confident that a CLA is required.
+// Uncertain verdicts count as required, so a single weak signal still does.
 func (d *Details) Required() bool {
-	return d.Known || d.Tag || d.BotFile || d.InContributing || d.InREADME || d.Action
+	return d.Confidence() > DefaultScoring.NotRequiredAt
+}
+
+// Confidence returns how sure DefaultScoring is that a CLA is required, from 0 to 1
+func (d *Details) Confidence() float64 {
+	return DefaultScoring.Confidence(*d)
+}
+
+// Verdict returns DefaultScoring's verdict
+func (d *Details) Verdict() Verdict {
+	return DefaultScoring.Verdict(*d)
+}
+
+// each calls fn with the result of every detector that bears on a CLA, which leaves out DCODetector
+func (d *Details) each(fn func(name string, r bool)) {
+	for _, name := range []string{KnownDetector, TagDetector, BotFileDetector, InContributingDetector, InREADMEDetector, ActionDetector} {
+		fn(name, *d.field(name))
+	}
+	for name, r := range d.Results {
+		if name != DCODetector {
+			fn(name, r)
+		}
+	}
+}
+
+// IsUnknown reports whether the detector with the given name couldn't tell either way
+func (d *Details) IsUnknown(name string) bool {
+	for _, n := range d.Unknown {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Result returns the result of the detector with the given name
+func (d *Details) Result(name string) bool {
+	if f := d.field(name); f != nil {
+		return *f
+	}
+	return d.Results[name]
+}
+
+func (d *Details) set(name string, r bool) {
+	if f := d.field(name); f != nil {
+		*f = *f || r
+		return
+	}
+	if d.Results == nil {
+		d.Results = make(map[string]bool)
+	}
+	d.Results[name] = d.Results[name] || r
+}
+
+func (d *Details) field(name string) *bool {
+	switch name {
+	case KnownDetector:
+		return &d.Known
+	case TagDetector:
+		return &d.Tag
+	case BotFileDetector:
+		return &d.BotFile
+	case InContributingDetector:
+		return &d.InContributing
+	case InREADMEDetector:
+		return &d.InREADME
+	case ActionDetector:
+		return &d.Action
+	}
+	return nil
 }
 
 func (d *Details) merge(details Details) {
@@ -34,4 +127,9 @@ func (d *Details) merge(details Details) {
 	d.InREADME = d.InREADME || details.InREADME
 	d.Known = d.Known || details.Known
 	d.Tag = d.Tag || details.Tag
+	for name, r := range details.Results {
+		d.set(name, r)
+	}
+	d.Unknown = append(d.Unknown, details.Unknown...)
+	d.Evidence = append(d.Evidence, details.Evidence...)
 }
@@ -32,6 +32,11 @@ func TestDetailsMerge(t *testing.T) {
 			Details{InREADME: true},
 			Details{InContributing: true, InREADME: true},
 		},
+		{
+			Details{Results: map[string]bool{"a": true}},
+			Details{Results: map[string]bool{"a": false, "b": true}},
+			Details{Results: map[string]bool{"a": true, "b": true}},
+		},
 	}
 	for _, tt := range tests {
 		tt.a.merge(tt.b)
@@ -0,0 +1,261 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Detector is a single heuristic for whether a repository requires a CLA
+type Detector interface {
+	// Name uniquely identifies the detector and keys its result in Details and Errors
+	Name() string
+	// Description is a short, human readable summary of what the detector looks for
+	Description() string
+	// Detect reports whether the snapshot shows signs of requiring a CLA
+	Detect(ctx context.Context, s *Snapshot) (bool, error)
+}
+
+// Names of the built-in detectors
+const (
+	KnownDetector          = "known-owner"
+	TagDetector            = "pr-label"
+	BotFileDetector        = "clabot-file"
+	InContributingDetector = "contributing"
+	InREADMEDetector       = "readme"
+	ActionDetector         = "cla-assistant-action"
+	NoCLADetector          = "no-cla"
+	EasyCLADetector        = "easycla"
+	SigningLinkDetector    = "signing-link"
+	StatusDetector         = "cla-status"
+	RequiredCheckDetector  = "required-check"
+	CommentDetector        = "bot-comment"
+	TemplateDetector       = "template"
+	// DCODetector looks for a Developer Certificate of Origin policy.
+	// Its result is read with Details.Result like any other, but doesn't count towards a CLA being required.
+	DCODetector = "dco"
+)
+
+type detector struct {
+	name        string
+	description string
+	detect      func(context.Context, *Snapshot) (bool, error)
+	// cost is nil for detectors that don't make API requests
+	cost func(*Snapshot) Cost
+}
+
+func (d detector) Name() string {
+	return d.name
+}
+
+func (d detector) Description() string {
+	return d.description
+}
+
+func (d detector) Detect(ctx context.Context, s *Snapshot) (bool, error) {
+	return d.detect(ctx, s)
+}
+
+func (d detector) Cost(s *Snapshot) Cost {
+	if d.cost == nil {
+		return Cost{}
+	}
+	return d.cost(s)
+}
+
+var registry struct {
+	sync.RWMutex
+	detectors []Detector
+}
+
+// Register adds a detector to the set run by DetailWithContext.
+// It panics if d is nil or a detector with the same name is already registered.
+func Register(d Detector) {
+	registry.Lock()
+	defer registry.Unlock()
+	if d == nil {
+		panic("needcla: Register detector is nil")
+	}
+	for _, r := range registry.detectors {
+		if r.Name() == d.Name() {
+			panic(fmt.Sprintf("needcla: Register called twice for detector %q", d.Name()))
+		}
+	}
+	registry.detectors = append(registry.detectors, d)
+}
+
+// Detectors returns the registered detectors in the order they were registered
+func Detectors() []Detector {
+	registry.RLock()
+	defer registry.RUnlock()
+	detectors := make([]Detector, len(registry.detectors))
+	copy(detectors, registry.detectors)
+	return detectors
+}
+
+func init() {
+	Register(detector{
+		name:        KnownDetector,
+		description: "owner is a known CLA requirer",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.isKnown(), nil
+		},
+	})
+	Register(detector{
+		name:        TagDetector,
+		description: `recent PRs have "cla" labels`,
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.hasCLATag(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			return Cost{Core: 1}
+		},
+	})
+	Register(detector{
+		name:        StatusDetector,
+		description: "recent PRs have CLA commit statuses or check runs",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.hasCLAStatus(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			// the PRs are shared with the label check, and each sampled commit's statuses and check runs are listed
+			if _, ok := s.forge.(StatusLister); ok {
+				return Cost{Core: 2 * statusSample}
+			}
+			return Cost{}
+		},
+	})
+	Register(detector{
+		name:        RequiredCheckDetector,
+		description: "the default branch requires a CLA status check",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.requiresCLACheck(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			// rulesets and branch protection
+			if _, ok := s.forge.(RequiredCheckLister); ok {
+				return Cost{Core: 2}
+			}
+			return Cost{}
+		},
+	})
+	Register(detector{
+		name:        CommentDetector,
+		description: "CLA bots comment on recent external contributors' PRs",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.hasCLABotComment(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			// the PRs are shared with the label check, and each sampled PR's comments are one page
+			if _, ok := s.forge.(CommentLister); ok {
+				return Cost{Core: commentSample}
+			}
+			return Cost{}
+		},
+	})
+	Register(detector{
+		name:        BotFileDetector,
+		description: ".clabot file exists",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.hasCLABotFile(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			return s.findCost(".clabot")
+		},
+	})
+	Register(detector{
+		name:        InContributingDetector,
+		description: "CONTRIBUTING references a CLA",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.referencesCLAInContributing(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			return s.docCost(contributingDoc)
+		},
+	})
+	Register(detector{
+		name:        InREADMEDetector,
+		description: "README references a CLA",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.referencesCLAInREADME(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			return s.docCost(readmeDoc)
+		},
+	})
+	Register(detector{
+		name:        TemplateDetector,
+		description: "a PR or issue template references a CLA",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.referencesCLAInTemplates(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			return s.templatesCost()
+		},
+	})
+	Register(detector{
+		name:        ActionDetector,
+		description: "a CI workflow runs a CLA check",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.usesCLAAssistantAction(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			return s.workflowsCost().add(s.contentCost(".gitlab-ci.yml"))
+		},
+	})
+	Register(detector{
+		name:        NoCLADetector,
+		description: "CONTRIBUTING or README says no CLA is needed",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.statesNoCLA(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			// the CONTRIBUTING.md and README.md detectors read the same files
+			return Cost{}
+		},
+	})
+	Register(detector{
+		name:        EasyCLADetector,
+		description: "the repo uses the Linux Foundation's EasyCLA",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.usesEasyCLA(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			// README.md, CONTRIBUTING.md, workflows, PRs and statuses are shared with other detectors
+			return s.filesCost(".github")
+		},
+	})
+	Register(detector{
+		name:        SigningLinkDetector,
+		description: "README, CONTRIBUTING or a CI workflow links to a CLA signing page",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.linksToSigningPage(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			// every file it reads is shared with other detectors
+			return Cost{}
+		},
+	})
+	Register(detector{
+		name:        DCODetector,
+		description: "commits are signed off for a DCO",
+		detect: func(ctx context.Context, s *Snapshot) (bool, error) {
+			return s.usesDCO(ctx)
+		},
+		cost: func(s *Snapshot) Cost {
+			// workflows and CONTRIBUTING.md are shared with the CLA detectors, the commits aren't
+			c := Cost{Core: 1}
+			for _, path := range dcoConfigFiles {
+				c = c.add(s.findCost(path))
+			}
+			return c
+		},
+	})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+type fakeDetector struct {
+	name string
+}
+
+func (f fakeDetector) Name() string        { return f.name }
+func (f fakeDetector) Description() string { return "fake detector" }
+func (f fakeDetector) Detect(ctx context.Context, s *needcla.Snapshot) (bool, error) {
+	return false, nil
+}
+
+func TestRegister(t *testing.T) {
+	needcla.Register(fakeDetector{"test-register"})
+
+	var found bool
+	for _, d := range needcla.Detectors() {
+		if d.Name() == "test-register" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("registered detector missing from Detectors()")
+	}
+
+	t.Run("Duplicate", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic registering %q twice", needcla.KnownDetector)
+			}
+		}()
+		needcla.Register(fakeDetector{needcla.KnownDetector})
+	})
+}
+
+func TestDetailsResult(t *testing.T) {
+	d := needcla.Details{
+		InREADME: true,
+		Results:  map[string]bool{needcla.EasyCLADetector: true, "custom": true},
+	}
+	if !d.Result(needcla.InREADMEDetector) {
+		t.Errorf("expected built-in result to be read from its field")
+	}
+	if !d.Result(needcla.EasyCLADetector) || !d.Result("custom") {
+		t.Errorf("expected other results to be read from Results")
+	}
+	if d.Result("missing") {
+		t.Errorf("expected false for an unknown detector")
+	}
+}
+
+func TestRegisterLanguage(t *testing.T) {
+	needcla.RegisterLanguage(needcla.Language{
+		Code: "eo",
+		CLA:  []needcla.Matcher{needcla.Phrase("cla-phrase-eo", "kontribuanta licenca interkonsento")},
+	})
+	fsys := fstest.MapFS{
+		"CONTRIBUTING.md": {Data: []byte("# Kontribui\n\nUnue subskribu la Kontribuanta Licenca Interkonsento.\n")},
+	}
+	d, err := needcla.DetailSource(context.Background(), needcla.NewFSSource(fsys), "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.InContributing || len(d.Evidence) == 0 || d.Evidence[0].Matcher != "cla-phrase-eo" || d.Evidence[0].Line != 3 {
+		t.Errorf("expected the registered language's phrase to be found, got %+v", d)
+	}
+
+	var codes []string
+	for _, l := range needcla.Languages() {
+		codes = append(codes, l.Code)
+	}
+	if strings.Join(codes, ",") != "en,zh,ja,de,fr,eo" {
+		t.Errorf("unexpected languages %v", codes)
+	}
+
+	t.Run("Duplicate", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic registering %q twice", "en")
+			}
+		}()
+		needcla.RegisterLanguage(needcla.Language{Code: "en", CLA: []needcla.Matcher{needcla.Phrase("cla", "CLA")}})
+	})
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Names of the community documents detectors read, resolved like GitHub does by readDoc
+const (
+	contributingDoc = "CONTRIBUTING"
+	readmeDoc       = "README"
+)
+
+// docDirs are where GitHub looks for community documents, in the order it prefers them
+// https://docs.github.com/en/communities/setting-up-your-project-for-healthy-contributions/creating-a-default-community-health-file
+var docDirs = []string{".github", "", "docs"}
+
+// orgDefaultsRepo is the repository GitHub takes an org's default community health files from
+const orgDefaultsRepo = ".github"
+
+// orgDocs are the documents GitHub takes from the org's defaults when a repository doesn't have its own.
+// READMEs aren't among them.
+var orgDocs = map[string]bool{contributingDoc: true}
+
+// orgCache holds the org's defaults repository once it's been looked up
+type orgCache struct {
+	once sync.Once
+	src  Source
+	err  error
+}
+
+// orgDefaults returns the owner's .github repository, or nil if it doesn't have one.
+// Only GitHub has org defaults.
+func (s *Snapshot) orgDefaults(ctx context.Context) (Source, error) {
+	gh, ok := s.forge.(*GitHub)
+	if !ok || s.owner == "" || s.repo == orgDefaultsRepo {
+		return nil, nil
+	}
+	fetch := func() (Source, error) {
+		branch, err := gh.DefaultBranch(ctx, s.owner, orgDefaultsRepo)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return gh.Source(ctx, s.owner, orgDefaultsRepo, branch)
+	}
+	if s.org == nil {
+		return fetch()
+	}
+	s.org.once.Do(func() {
+		s.org.src, s.org.err = fetch()
+	})
+	return s.org.src, s.org.err
+}
+
+// docKey keys a resolved document in the file cache, apart from any path
+func docKey(name string) string {
+	return "\x00" + name
+}
+
+// findDoc returns the entry GitHub would use for the document name in src, like "CONTRIBUTING", or nil if there isn't one.
+// Names match case-insensitively with any extension. In the same directory the canonical file wins over translations
+// like README.de.md, then Markdown wins over other extensions.
+func findDoc(ctx context.Context, src Source, name string) (*Entry, error) {
+	for _, dir := range docDirs {
+		entries, err := src.List(ctx, dir)
+		if err == ErrTruncatedTree {
+			return nil, fmt.Errorf("tree was truncated and %s was possibly missed", joinPath(dir, name))
+		}
+		if err != nil {
+			return nil, err
+		}
+		var matches []*Entry
+		for _, e := range entries {
+			if !e.Dir && isDoc(e.Path, name) {
+				matches = append(matches, e)
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		sort.SliceStable(matches, func(i, j int) bool {
+			ci, cj := isCanonicalDoc(matches[i].Path, name), isCanonicalDoc(matches[j].Path, name)
+			if ci != cj {
+				return ci
+			}
+			mi, mj := isMarkdown(matches[i].Path), isMarkdown(matches[j].Path)
+			if mi != mj {
+				return mi
+			}
+			return matches[i].Path < matches[j].Path
+		})
+		return matches[0], nil
+	}
+	return nil, nil
+}
+
+// isDoc reports whether path is the document name, with or without an extension
+func isDoc(path, name string) bool {
+	_, base := splitPath(path)
+	base, name = strings.ToLower(base), strings.ToLower(name)
+	return base == name || strings.HasPrefix(base, name+".")
+}
+
+// isCanonicalDoc reports whether path is the document name with at most one extension, rather than a translation like CONTRIBUTING.ja.md
+func isCanonicalDoc(path, name string) bool {
+	_, base := splitPath(path)
+	return strings.Count(base[len(name):], ".") <= 1
+}
+
+func isMarkdown(path string) bool {
+	path = strings.ToLower(path)
+	return strings.HasSuffix(path, ".md") || strings.HasSuffix(path, ".markdown")
+}
+
+// readDoc returns the entry and contents of the document name, resolved with findDoc, or nils if there isn't one.
+// Documents in orgDocs fall back to the org's defaults, setting the entry's Repo.
+func (s *Snapshot) readDoc(ctx context.Context, name string) (*Entry, []byte, error) {
+	fetch := func() (*Entry, []byte, error) {
+		e, err := findDoc(ctx, s.src, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if e == nil {
+			if e, err = s.findOrgDoc(ctx, name); e == nil || err != nil {
+				return nil, nil, err
+			}
+		}
+		content, err := s.readEntry(ctx, e)
+		return e, content, err
+	}
+	if s.files == nil {
+		return fetch()
+	}
+	return s.files.read(docKey(name), fetch)
+}
+
+// findOrgDoc returns the entry for the document name in the org's defaults,
+// or nil if it isn't one GitHub takes from them or they don't have it
+func (s *Snapshot) findOrgDoc(ctx context.Context, name string) (*Entry, error) {
+	if !orgDocs[name] {
+		return nil, nil
+	}
+	org, err := s.orgDefaults(ctx)
+	if org == nil || err != nil {
+		return nil, err
+	}
+	e, err := findDoc(ctx, org, name)
+	if e == nil || err != nil {
+		if err != nil {
+			err = fmt.Errorf("failed to check %s/%s: %v", s.owner, orgDefaultsRepo, err)
+		}
+		return nil, err
+	}
+	oe := *e
+	oe.Repo = s.owner + "/" + orgDefaultsRepo
+	return &oe, nil
+}
+
+// docCost is the worst-case cost of s.readDoc(name)
+func (s *Snapshot) docCost(name string) Cost {
+	c, ok := s.src.(sourceCoster)
+	if !ok {
+		return Cost{}
+	}
+	cost := c.readCost()
+	for _, dir := range docDirs {
+		cost = cost.add(c.listCost(dir))
+	}
+	if _, ok := s.forge.(*GitHub); ok && orgDocs[name] {
+		// the org defaults' default branch, commit and tree
+		cost = cost.add(snapshotCost)
+	}
+	return cost
+}
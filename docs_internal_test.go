@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-github/v43/github"
+)
+
+func TestFindDoc(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"root", []string{"CONTRIBUTING.md"}, "CONTRIBUTING.md"},
+		{"lowercase with another extension", []string{"contributing.rst"}, "contributing.rst"},
+		{"no extension", []string{"Contributing"}, "Contributing"},
+		{".github wins over root", []string{"CONTRIBUTING.md", ".github/CONTRIBUTING.md"}, ".github/CONTRIBUTING.md"},
+		{"root wins over docs", []string{"docs/CONTRIBUTING.md", "CONTRIBUTING.adoc"}, "CONTRIBUTING.adoc"},
+		{"docs", []string{"docs/contributing.md"}, "docs/contributing.md"},
+		{"markdown wins", []string{"CONTRIBUTING.adoc", "CONTRIBUTING.md"}, "CONTRIBUTING.md"},
+		{"canonical wins over translations", []string{"CONTRIBUTING.ja.md", "CONTRIBUTING.md", "CONTRIBUTING.de.md"}, "CONTRIBUTING.md"},
+		{"canonical wins over markdown translations", []string{"CONTRIBUTING.zh-CN.md", "CONTRIBUTING.rst"}, "CONTRIBUTING.rst"},
+		{"translation", []string{"CONTRIBUTING.fr.md"}, "CONTRIBUTING.fr.md"},
+		{"other names", []string{"CONTRIBUTING_GUIDE.md", "docs/CONTRIBUTORS.md"}, ""},
+		{"missing", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, path := range tt.files {
+				fsys[path] = &fstest.MapFile{Data: []byte("contributing")}
+			}
+			s := &Snapshot{src: NewFSSource(fsys)}
+			e, err := findDoc(context.Background(), s.src, contributingDoc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got string
+			if e != nil {
+				got = e.Path
+			}
+			if got != tt.want {
+				t.Errorf("got %q, wanted %q", got, tt.want)
+			}
+		})
+	}
+
+	src := NewFSSource(fstest.MapFS{"README.de.md": {Data: []byte("# Beispiel")}, "README.md": {Data: []byte("# Example")}})
+	if e, err := findDoc(context.Background(), src, readmeDoc); err != nil || e == nil || e.Path != "README.md" {
+		t.Errorf("expected the canonical README over its translation, got %+v, %v", e, err)
+	}
+}
+
+func TestDetailDocs(t *testing.T) {
+	fsys := fstest.MapFS{
+		".github/CONTRIBUTING.md": {Data: []byte("Please sign our Contributor License Agreement.\n")},
+		"readme.rst":              {Data: []byte("Example\n=======\n")},
+	}
+	s := &Snapshot{src: NewFSSource(fsys)}
+	d, err := s.detail(context.Background(), Detectors(), new(Errors))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.InContributing {
+		t.Errorf("expected .github/CONTRIBUTING.md to be checked, got %+v", d)
+	}
+	if d.Docs[contributingDoc] != ".github/CONTRIBUTING.md" || d.Docs[readmeDoc] != "readme.rst" {
+		t.Errorf("unexpected docs %v", d.Docs)
+	}
+	var found bool
+	for _, ev := range d.Evidence {
+		if ev.Detector == InContributingDetector && ev.Path == ".github/CONTRIBUTING.md" && ev.Line == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected evidence with the path that was used, got %+v", d.Evidence)
+	}
+}
+
+func TestReadDocOrgDefaults(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/.github", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, github.Repository{DefaultBranch: github.String("main")})
+	})
+	mux.HandleFunc("/repos/o/.github/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123456789abcdef0123456789abcdef01234567"))
+	})
+	mux.HandleFunc("/repos/o/.github/git/trees/0123456789abcdef0123456789abcdef01234567", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, github.Tree{Entries: []*github.TreeEntry{
+			{Path: github.String("CONTRIBUTING.md"), Type: github.String("blob"), SHA: github.String("contributing")},
+			{Path: github.String("README.md"), Type: github.String("blob"), SHA: github.String("readme")},
+		}})
+	})
+	mux.HandleFunc("/repos/o/.github/git/blobs/contributing", func(w http.ResponseWriter, r *http.Request) {
+		content := base64.StdEncoding.EncodeToString([]byte("# Contributing\n\nYou must sign a Contributor License Agreement.\n"))
+		writeJSON(w, github.Blob{Content: github.String(content), Encoding: github.String("base64")})
+	})
+
+	s := &Snapshot{
+		owner: "o",
+		repo:  "r",
+		forge: GitHubForge(newTestClient(t, mux)),
+		src:   NewFSSource(fstest.MapFS{"README.md": {Data: []byte("# r\n")}}),
+	}
+	var detectors []Detector
+	for _, det := range Detectors() {
+		if det.Name() == InContributingDetector || det.Name() == InREADMEDetector {
+			detectors = append(detectors, det)
+		}
+	}
+	d, err := s.detail(context.Background(), detectors, new(Errors))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.InContributing || d.InREADME {
+		t.Errorf("expected only the org's CONTRIBUTING.md to reference a CLA, got %+v", d)
+	}
+	want := Evidence{Detector: InContributingDetector, Repo: "o/.github", Path: "CONTRIBUTING.md", SHA: "contributing", Line: 3, Snippet: "You must sign a Contributor License Agreement.", Matcher: "cla-phrase", Heading: "Contributing"}
+	if len(d.Evidence) != 1 || d.Evidence[0] != want {
+		t.Errorf("got evidence %+v, wanted %+v", d.Evidence, want)
+	}
+	if d.Docs[contributingDoc] != "o/.github/CONTRIBUTING.md" || d.Docs[readmeDoc] != "README.md" {
+		t.Errorf("unexpected docs %v", d.Docs)
+	}
+}
+
+func TestReadDocNoOrgDefaults(t *testing.T) {
+	mux := http.NewServeMux()
+	s := &Snapshot{
+		owner: "o",
+		repo:  "r",
+		forge: GitHubForge(newTestClient(t, mux)),
+		src:   NewFSSource(fstest.MapFS{}),
+	}
+	e, content, err := s.readDoc(context.Background(), contributingDoc)
+	if e != nil || content != nil || err != nil {
+		t.Errorf("expected no CONTRIBUTING without org defaults, got %+v, %q, %v", e, content, err)
+	}
+}
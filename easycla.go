@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// usesEasyCLA looks for EasyCLA badges and links in README and CONTRIBUTING,
+// .github files and workflows that reference it, and statuses its bot posted on recent pull requests
+func (s *Snapshot) usesEasyCLA(ctx context.Context) (bool, error) {
+	var errs []string
+	for _, name := range []string{readmeDoc, contributingDoc} {
+		e, content, err := s.readDoc(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("* %s: %v", name, err))
+			continue
+		}
+		if e != nil && s.matchEasyCLA(e, content, easyCLALinkMatchers) {
+			return true, nil
+		}
+	}
+
+	for _, dir := range []string{".github", ".github/workflows"} {
+		entries, err := s.list(ctx, dir)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("* %s: %v", dir, err))
+			continue
+		}
+		for _, e := range entries {
+			if e.Dir {
+				continue
+			}
+			content, err := s.readEntry(ctx, e)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("* %s: %v", e.Path, err))
+				continue
+			}
+			if s.matchEasyCLA(e, content, easyCLAMatchers) {
+				return true, nil
+			}
+		}
+	}
+
+	found, err := s.easyCLAStatuses(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("* recent PR statuses: %v", err))
+	}
+	if len(errs) != 0 && !found {
+		return false, fmt.Errorf("%d error(s) checking for EasyCLA:\n\t%s", len(errs), strings.Join(errs, "\n\t"))
+	}
+	return found, nil
+}
+
+// matchEasyCLA reports whether any of matchers match content, read from e, recording where as evidence
+func (s *Snapshot) matchEasyCLA(e *Entry, content []byte, matchers matcherSet) bool {
+	ev := matchers.match(content)
+	if ev == nil {
+		return false
+	}
+	ev.at(e)
+	ev.Provider = ProviderEasyCLA
+	if strings.HasPrefix(e.Path, ".github/workflows/") {
+		ev.Step = workflowStep(content, ev.Line)
+	}
+	s.AddEvidence(*ev)
+	return true
+}
+
+// easyCLAStatuses looks for the EasyCLA bot's statuses or check runs on the head commits of recent pull requests
+func (s *Snapshot) easyCLAStatuses(ctx context.Context) (bool, error) {
+	sampled, err := s.recentStatuses(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, ps := range sampled {
+		for _, st := range ps.statuses {
+			if isEasyCLAStatus(st) {
+				s.AddEvidence(Evidence{
+					PullRequest: ps.pr.Number,
+					SHA:         ps.pr.HeadSHA,
+					Status:      st.Context,
+					Snippet:     st.Creator,
+					Provider:    ProviderEasyCLA,
+				})
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// isEasyCLAStatus reports whether st was posted by the linux-foundation-easycla bot
+func isEasyCLAStatus(st Status) bool {
+	creator := strings.ToLower(strings.TrimSuffix(st.Creator, "[bot]"))
+	return creator == "linux-foundation-easycla" || strings.EqualFold(st.Context, ProviderEasyCLA)
+}
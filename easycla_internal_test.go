@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-github/v43/github"
+)
+
+func TestEasyCLAStatuses(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []*github.PullRequest{
+			{Number: github.Int(2), Head: &github.PullRequestBranch{SHA: github.String("two")}},
+			{Number: github.Int(1), Head: &github.PullRequestBranch{SHA: github.String("one")}},
+		})
+	})
+	mux.HandleFunc("/repos/o/r/commits/two/statuses", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []*github.RepoStatus{{Context: github.String("ci/build"), State: github.String("success")}})
+	})
+	mux.HandleFunc("/repos/o/r/commits/two/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, github.ListCheckRunsResults{})
+	})
+	mux.HandleFunc("/repos/o/r/commits/one/statuses", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []*github.RepoStatus{})
+	})
+	mux.HandleFunc("/repos/o/r/commits/one/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, github.ListCheckRunsResults{
+			Total: github.Int(1),
+			CheckRuns: []*github.CheckRun{{
+				Name:       github.String("EasyCLA"),
+				Conclusion: github.String("success"),
+				App:        &github.App{Slug: github.String("linux-foundation-easycla")},
+			}},
+		})
+	})
+
+	s := &Snapshot{
+		owner:    "o",
+		repo:     "r",
+		forge:    GitHubForge(newTestClient(t, mux)),
+		src:      NewFSSource(fstest.MapFS{"README.md": {Data: []byte("# r\n")}}),
+		evidence: new(evidenceLog),
+		detector: EasyCLADetector,
+	}
+	found, err := s.usesEasyCLA(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !found {
+		t.Fatal("expected the EasyCLA check run to be found")
+	}
+	want := Evidence{Detector: EasyCLADetector, PullRequest: 1, SHA: "one", Status: "EasyCLA", Snippet: "linux-foundation-easycla", Provider: ProviderEasyCLA}
+	if got := s.evidence.list(); len(got) != 1 || got[0] != want {
+		t.Errorf("got evidence %+v, wanted %+v", got, want)
+	}
+}
+
+func TestIsEasyCLAStatus(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   bool
+	}{
+		{Status{Context: "EasyCLA", Creator: "linux-foundation-easycla[bot]"}, true},
+		{Status{Context: "license/cla", Creator: "linux-foundation-easycla"}, true},
+		{Status{Context: "easycla"}, true},
+		{Status{Context: "license/cla", Creator: "CLAassistant"}, false},
+	}
+	for _, tt := range tests {
+		if got := isEasyCLAStatus(tt.status); got != tt.want {
+			t.Errorf("isEasyCLAStatus(%+v) = %v, wanted %v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -8,14 +8,23 @@ the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/
 package needcla
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 )
 
 var ErrTruncatedTree = errors.New("git tree was truncated and path was possibly missed")
 var ErrInvalidToken = errors.New("invalid personal access token")
 var ErrNotFound = errors.New("not found")
+var ErrInvalidReference = errors.New("invalid repository reference")
+var ErrUnresolvedDependency = errors.New("source repository not found")
+
+// ErrUnknown is returned, possibly wrapped, by detectors that can't tell either way,
+// like when the token can't read branch protection.
+// Their results are listed in Details.Unknown instead of Errors.
+var ErrUnknown = errors.New("result unknown")
 
 // Errors returns errors from checking for CLA references
 // adapted from hashicorp/go-multierror
@@ -31,6 +40,47 @@ type Errors struct {
 	InREADMEErr error
 	// ActionErr is non-nil if there was an error checking for `Details.Action`
 	ActionErr error
+	// Errs holds the errors from detectors without a field above, built in or registered, keyed by detector name,
+	// like Errs[StatusDetector]. Detectors that couldn't tell either way are listed in `Details.Unknown` instead.
+	Errs map[string]error
+}
+
+// Err returns the error from the detector with the given name
+func (e *Errors) Err(name string) error {
+	if f := e.field(name); f != nil {
+		return *f
+	}
+	return e.Errs[name]
+}
+
+func (e *Errors) set(name string, err error) {
+	if err == nil {
+		return
+	}
+	if f := e.field(name); f != nil {
+		*f = err
+		return
+	}
+	if e.Errs == nil {
+		e.Errs = make(map[string]error)
+	}
+	e.Errs[name] = err
+}
+
+func (e *Errors) field(name string) *error {
+	switch name {
+	case TagDetector:
+		return &e.TagErr
+	case BotFileDetector:
+		return &e.BotFileErr
+	case InContributingDetector:
+		return &e.InContributingErr
+	case InREADMEDetector:
+		return &e.InREADMEErr
+	case ActionDetector:
+		return &e.ActionErr
+	}
+	return nil
 }
 
 func (e *Errors) merge(errors Errors) {
@@ -49,6 +99,9 @@ func (e *Errors) merge(errors Errors) {
 	if errors.ActionErr != nil {
 		e.ActionErr = errors.ActionErr
 	}
+	for name, err := range errors.Errs {
+		e.set(name, err)
+	}
 }
 
 func (e Errors) Error() string {
@@ -68,11 +121,74 @@ func (e Errors) Error() string {
 	if e.ActionErr != nil {
 		lines = append(lines, fmt.Sprintf("* checking for cla-assistant Action: %v", e.ActionErr))
 	}
+	// registered detectors are described in registration order, then any others by name
+	listed := make(map[string]bool, len(e.Errs))
+	for _, det := range Detectors() {
+		if err, ok := e.Errs[det.Name()]; ok {
+			lines = append(lines, fmt.Sprintf("* checking whether %s: %v", det.Description(), err))
+			listed[det.Name()] = true
+		}
+	}
+	names := make([]string, 0, len(e.Errs))
+	for name := range e.Errs {
+		if !listed[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("* checking %s: %v", name, e.Errs[name]))
+	}
 	return fmt.Sprintf("%d error(s) checking for CLA references:\n\t%s", len(lines), strings.Join(lines, "\n\t"))
 }
 
+// MarshalJSON encodes each non-nil error as its message, keyed by detector name like Details
+func (e Errors) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{})
+	for _, name := range []string{TagDetector, BotFileDetector, InContributingDetector, InREADMEDetector, ActionDetector} {
+		if err := e.Err(name); err != nil {
+			m[name] = err.Error()
+		}
+	}
+	if len(e.Errs) != 0 {
+		results := make(map[string]string, len(e.Errs))
+		for name, err := range e.Errs {
+			results[name] = err.Error()
+		}
+		m["results"] = results
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes the messages written by MarshalJSON into errors
+func (e *Errors) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*e = Errors{}
+	for name, raw := range m {
+		if name == "results" {
+			var results map[string]string
+			if err := json.Unmarshal(raw, &results); err != nil {
+				return err
+			}
+			for n, msg := range results {
+				e.set(n, errors.New(msg))
+			}
+			continue
+		}
+		var msg string
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return err
+		}
+		e.set(name, errors.New(msg))
+	}
+	return nil
+}
+
 func (e *Errors) ErrOrNil() error {
-	if e.TagErr == nil && e.BotFileErr == nil && e.InContributingErr == nil && e.InREADMEErr == nil && e.ActionErr == nil {
+	if e.TagErr == nil && e.BotFileErr == nil && e.InContributingErr == nil && e.InREADMEErr == nil && e.ActionErr == nil && len(e.Errs) == 0 {
 		return nil
 	}
 	return e
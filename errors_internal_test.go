@@ -34,6 +34,11 @@ func TestErrorsMerge(t *testing.T) {
 			Errors{InREADMEErr: err},
 			Errors{InContributingErr: err, InREADMEErr: err},
 		},
+		{
+			Errors{},
+			Errors{Errs: map[string]error{"custom": err}},
+			Errors{Errs: map[string]error{"custom": err}},
+		},
 	}
 	for _, tt := range tests {
 		tt.a.merge(tt.b)
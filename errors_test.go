@@ -8,6 +8,7 @@ the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/
 package needcla
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 )
@@ -30,3 +31,48 @@ func TestErrorsError(t *testing.T) {
 		t.Errorf("unexpected error string,\nexpected:\n---\n%s\n---\n\ngot:\n---\n%s\n---", expected, e.Error())
 	}
 }
+
+func TestErrorsErrorResults(t *testing.T) {
+	e := Errors{
+		TagErr: fmt.Errorf("this is the tag error"),
+		Errs: map[string]error{
+			"unregistered":   fmt.Errorf("this is a custom error"),
+			StatusDetector:   fmt.Errorf("this is the status error"),
+			TemplateDetector: fmt.Errorf("this is the template error"),
+		},
+	}
+	expected := "4 error(s) checking for CLA references:\n\t" +
+		"* checking for CLA tag: this is the tag error\n\t" +
+		"* checking whether recent PRs have CLA commit statuses or check runs: this is the status error\n\t" +
+		"* checking whether a PR or issue template references a CLA: this is the template error\n\t" +
+		"* checking unregistered: this is a custom error"
+	if e.Error() != expected {
+		t.Errorf("unexpected error string,\nexpected:\n---\n%s\n---\n\ngot:\n---\n%s\n---", expected, e.Error())
+	}
+	if e.Err(StatusDetector) == nil || e.ErrOrNil() == nil {
+		t.Errorf("expected the status error to be read from Errs")
+	}
+}
+
+func TestErrorsJSON(t *testing.T) {
+	e := Errors{
+		TagErr: fmt.Errorf("this is the tag error"),
+		Errs:   map[string]error{"custom": fmt.Errorf("this is a custom error")},
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"pr-label":"this is the tag error","results":{"custom":"this is a custom error"}}`
+	if string(b) != expected {
+		t.Errorf("unexpected json,\nexpected: %s\ngot:      %s", expected, b)
+	}
+
+	var decoded Errors
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Error() != e.Error() {
+		t.Errorf("round trip changed the errors,\nexpected:\n%s\ngot:\n%s", e.Error(), decoded.Error())
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxSnippet is the longest snippet of a matched line kept as evidence
+const maxSnippet = 200
+
+// truncateSnippet cuts s to at most maxSnippet bytes without splitting a UTF-8 character
+func truncateSnippet(s string) string {
+	if len(s) <= maxSnippet {
+		return s
+	}
+	i := maxSnippet
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return s[:i]
+}
+
+// stepName matches the name of a CI step or job
+var stepName = regexp.MustCompile(`^\s*(?:-\s+)?name:\s*["']?(.*?)["']?\s*$`)
+
+// Evidence is what made a detector report a CLA, so a match on a stray "CLA" acronym
+// can be told apart from a real CLA policy.
+// Only the fields that apply to the detector are set.
+type Evidence struct {
+	// Detector is the name of the detector that found the evidence
+	Detector string `json:"detector"`
+	// Repo is the owner/name of the repository Path is in, if it isn't the one being checked,
+	// like the org's .github repository GitHub takes default community health files from
+	Repo string `json:"repo,omitempty"`
+	// Path is the file that matched
+	Path string `json:"path,omitempty"`
+	// SHA is the git object ID of the file, if the Source knows it
+	SHA string `json:"sha,omitempty"`
+	// Line is the 1-based line number of the match in Path
+	Line int `json:"line,omitempty"`
+	// Snippet is the text that matched, usually the whole line
+	Snippet string `json:"snippet,omitempty"`
+	// Matcher is the name of the matcher that matched Snippet, like "cla-phrase"
+	Matcher string `json:"matcher,omitempty"`
+	// Heading is the path of Markdown or reStructuredText headings the match was under,
+	// like "Contributing > Contributor License Agreement"
+	Heading string `json:"heading,omitempty"`
+	// PullRequest is the number of the pull request that matched
+	PullRequest int `json:"pull_request,omitempty"`
+	// Label is the pull request label that matched
+	Label string `json:"label,omitempty"`
+	// Step is the CI workflow step or job that matched
+	Step string `json:"step,omitempty"`
+	// Status is the commit status context or check run name that matched
+	Status string `json:"status,omitempty"`
+	// URL is the CLA signing link that matched
+	URL string `json:"url,omitempty"`
+	// Provider is the CLA service the evidence points to, like ProviderEasyCLA
+	Provider string `json:"provider,omitempty"`
+}
+
+func (e Evidence) String() string {
+	var parts []string
+	path := e.Path
+	if e.Repo != "" && path != "" {
+		path = e.Repo + "/" + path
+	}
+	switch {
+	case path != "" && e.Line != 0:
+		parts = append(parts, fmt.Sprintf("%s:%d", path, e.Line))
+	case path != "":
+		parts = append(parts, path)
+	}
+	if e.SHA != "" {
+		sha := e.SHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		parts = append(parts, fmt.Sprintf("(%s)", sha))
+	}
+	if e.PullRequest != 0 {
+		parts = append(parts, fmt.Sprintf("PR #%d", e.PullRequest))
+	}
+	if e.Label != "" {
+		parts = append(parts, fmt.Sprintf("label %q", e.Label))
+	}
+	if e.Step != "" {
+		parts = append(parts, fmt.Sprintf("step %q", e.Step))
+	}
+	if e.Heading != "" {
+		parts = append(parts, fmt.Sprintf("under %q", e.Heading))
+	}
+	if e.Status != "" {
+		parts = append(parts, fmt.Sprintf("status %q", e.Status))
+	}
+	if e.URL != "" {
+		parts = append(parts, e.URL)
+	}
+	if e.Snippet != "" {
+		parts = append(parts, fmt.Sprintf("%q", e.Snippet))
+	}
+	return strings.Join(parts, " ")
+}
+
+// at sets where the evidence was found to the file e
+func (e *Evidence) at(entry *Entry) {
+	e.Path, e.SHA, e.Repo = entry.Path, entry.SHA, entry.Repo
+}
+
+// evidenceLog collects the evidence of one detector, which may record it from several goroutines
+type evidenceLog struct {
+	mu    sync.Mutex
+	items []Evidence
+}
+
+func (l *evidenceLog) add(e Evidence) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.items = append(l.items, e)
+}
+
+func (l *evidenceLog) list() []Evidence {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Evidence(nil), l.items...)
+}
+
+// workflowStep returns the name of the GitHub Actions step containing line,
+// falling back to the step's first line when it isn't named
+func workflowStep(content []byte, line int) string {
+	lines := strings.Split(string(content), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	// steps are list items, so walk back to the "- " that starts this one
+	start := line - 1
+	for ; start > 0; start-- {
+		if strings.HasPrefix(strings.TrimSpace(lines[start]), "- ") {
+			break
+		}
+	}
+	indent := len(lines[start]) - len(strings.TrimLeft(lines[start], " "))
+	for i := start; i < len(lines); i++ {
+		l := lines[i]
+		if i > start && strings.TrimSpace(l) != "" && len(l)-len(strings.TrimLeft(l, " ")) <= indent {
+			break
+		}
+		if m := stepName.FindStringSubmatch(l); m != nil {
+			return m[1]
+		}
+	}
+	return strings.TrimPrefix(strings.TrimSpace(lines[start]), "- ")
+}
+
+// gitlabJob returns the name of the job defined on line of a .gitlab-ci.yml, or "" if line doesn't define one
+func gitlabJob(content []byte, line int) string {
+	lines := strings.Split(string(content), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	l := lines[line-1]
+	if strings.HasPrefix(l, " ") || !strings.HasSuffix(strings.TrimSpace(l), ":") {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSpace(l), ":")
+}
@@ -0,0 +1,55 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWorkflowStep(t *testing.T) {
+	content := []byte(`jobs:
+  cla:
+    steps:
+      - uses: actions/checkout@v3
+      - name: "CLA Assistant"
+        if: github.event_name == 'pull_request_target'
+        uses: cla-assistant/github-action@v2.1.3-beta
+      - uses: cla-assistant/github-action@v2.1.3-beta
+`)
+	if step := workflowStep(content, 7); step != "CLA Assistant" {
+		t.Errorf("expected the named step, got %q", step)
+	}
+	if step := workflowStep(content, 8); step != "uses: cla-assistant/github-action@v2.1.3-beta" {
+		t.Errorf("expected the unnamed step's first line, got %q", step)
+	}
+	if job := gitlabJob([]byte("stages: [test]\ncla-check:\n  script: check\n"), 2); job != "cla-check" {
+		t.Errorf("expected the gitlab job name, got %q", job)
+	}
+}
+
+func TestDetailEvidence(t *testing.T) {
+	src := NewFSSource(fstest.MapFS{
+		".clabot":   {Data: []byte("{}")},
+		"README.md": {Data: []byte("# Example\n\nContributions need a signed Contributor License Agreement.\n")},
+	})
+	d, err := DetailSource(context.Background(), src, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Evidence) != 2 {
+		t.Fatalf("expected evidence from two detectors, got %+v", d.Evidence)
+	}
+	if ev := d.Evidence[0]; ev.Detector != BotFileDetector || ev.Path != ".clabot" {
+		t.Errorf("unexpected .clabot evidence %+v", ev)
+	}
+	if ev := d.Evidence[1]; ev.Detector != InREADMEDetector || ev.Path != "README.md" || ev.Line != 3 {
+		t.Errorf("unexpected README.md evidence %+v", ev)
+	}
+}
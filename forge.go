@@ -0,0 +1,275 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Forge is a code hosting service that repositories can be checked on
+type Forge interface {
+	// DefaultBranch returns the name of the repository's default branch
+	DefaultBranch(ctx context.Context, owner, repo string) (string, error)
+	// Source returns the files of the repository at branch
+	Source(ctx context.Context, owner, repo, branch string) (Source, error)
+	// PullRequests returns a sample of the repository's most recent pull or merge requests
+	PullRequests(ctx context.Context, owner, repo string) ([]PullRequest, error)
+}
+
+// CommitLister is implemented by forges that can list a branch's recent commits
+type CommitLister interface {
+	// Commits returns up to n of the most recent commits on branch, newest first
+	Commits(ctx context.Context, owner, repo, branch string, n int) ([]Commit, error)
+}
+
+// Commit is a commit on a forge
+type Commit struct {
+	SHA     string
+	Message string
+}
+
+// StatusLister is implemented by forges that report the commit statuses and check runs posted on a commit
+type StatusLister interface {
+	// Statuses returns the statuses and check runs on the commit ref
+	Statuses(ctx context.Context, owner, repo, ref string) ([]Status, error)
+}
+
+// RequiredCheckLister is implemented by forges that report the status checks a branch requires before merging
+type RequiredCheckLister interface {
+	// RequiredChecks returns the contexts of the status checks required on branch,
+	// from branch protection and rulesets.
+	// If the token can't read some of them, it returns those it could read and an error wrapping ErrUnknown.
+	RequiredChecks(ctx context.Context, owner, repo, branch string) ([]string, error)
+}
+
+// CommentLister is implemented by forges that list the comments on a pull request
+type CommentLister interface {
+	// Comments returns up to limit of the earliest comments on pull request number
+	Comments(ctx context.Context, owner, repo string, number, limit int) ([]Comment, error)
+}
+
+// Comment is a comment on a pull request
+type Comment struct {
+	// Author is the login of whoever left the comment, like "CLAassistant"
+	Author string
+	// Body is the comment's Markdown
+	Body string
+	// URL links to the comment
+	URL string
+}
+
+// Status is a commit status or check run posted by a CI system or bot
+type Status struct {
+	// Context is the status context or check run name, like "license/cla"
+	Context string
+	// Creator is the login or app slug of whatever posted the status, like "linux-foundation-easycla"
+	Creator string
+	// State is the status state or check run conclusion, like "success"
+	State string
+	// URL links to the status details
+	URL string
+}
+
+// PullRequest is a pull request, or a merge request on forges that call them that
+type PullRequest struct {
+	Number int
+	Labels []string
+	// HeadSHA is the commit at the tip of the pull request, if the forge reports it
+	HeadSHA string
+	// External is true if the author isn't a member, owner or collaborator of the repository,
+	// and is false if the forge doesn't say
+	External bool
+}
+
+// Kinds of forge supported by NewForge
+const (
+	ForgeGitHub    = "github"
+	ForgeGitLab    = "gitlab"
+	ForgeGitea     = "gitea"
+	ForgeBitbucket = "bitbucket"
+)
+
+// forgeHosts are the well known public instances of each kind of forge
+var forgeHosts = map[string]string{
+	"github.com":    ForgeGitHub,
+	"gitlab.com":    ForgeGitLab,
+	"codeberg.org":  ForgeGitea,
+	"gitea.com":     ForgeGitea,
+	"bitbucket.org": ForgeBitbucket,
+}
+
+// forgeDefaults are the base URLs used when NewForge isn't given one
+var forgeDefaults = map[string]string{
+	ForgeGitHub:    "https://github.com",
+	ForgeGitLab:    "https://gitlab.com",
+	ForgeGitea:     "https://codeberg.org",
+	ForgeBitbucket: "https://bitbucket.org",
+}
+
+// ForgeKind returns the kind of forge hosted at host, or "" if it isn't a well known instance
+func ForgeKind(host string) string {
+	return forgeHosts[strings.ToLower(strings.TrimPrefix(host, "www."))]
+}
+
+// ForgeURL returns the base URL of the best known public instance of a kind of forge, or "" if the kind is unknown
+func ForgeURL(kind string) string {
+	return forgeDefaults[kind]
+}
+
+// NewForge returns a Forge of the given kind for the instance at baseURL, like https://codeberg.org.
+// If baseURL is empty, the best known public instance of the kind is used.
+// token is a personal access token and may be empty to check public repositories.
+// If httpClient is nil, http.DefaultClient is used.
+func NewForge(httpClient *http.Client, kind string, baseURL string, token string) (Forge, error) {
+	if baseURL == "" {
+		baseURL = forgeDefaults[kind]
+	}
+	switch kind {
+	case ForgeGitHub:
+		return NewGitHub(httpClient, baseURL, token)
+	case ForgeGitLab:
+		return NewGitLab(httpClient, baseURL, token)
+	case ForgeGitea:
+		return NewGitea(httpClient, baseURL, token)
+	case ForgeBitbucket:
+		return NewBitbucket(httpClient, baseURL, token)
+	}
+	return nil, fmt.Errorf("unknown forge %q, expected %s, %s, %s or %s", kind, ForgeGitHub, ForgeGitLab, ForgeGitea, ForgeBitbucket)
+}
+
+// DetailForge checks a repository hosted on f.
+// GitHub repositories are checked with DetailWithOptions, so the rate limit is respected.
+func DetailForge(ctx context.Context, f Forge, owner string, repo string) (Details, error) {
+	return DetailForgeWithOptions(ctx, f, owner, repo, Options{})
+}
+
+// DetailForgeWithOptions is DetailForge with options.
+// The budget options only apply to GitHub, which is checked with DetailWithOptions.
+func DetailForgeWithOptions(ctx context.Context, f Forge, owner string, repo string, opts Options) (Details, error) {
+	if gh, ok := f.(*GitHub); ok {
+		return DetailWithOptions(ctx, gh.client, owner, repo, opts)
+	}
+	branch, err := f.DefaultBranch(ctx, owner, repo)
+	if err != nil {
+		return Details{}, err
+	}
+	src, err := f.Source(ctx, owner, repo, branch)
+	if err != nil {
+		return Details{}, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	s := &Snapshot{
+		owner:  owner,
+		repo:   repo,
+		branch: branch,
+		src:    src,
+		forge:  f,
+
+		statusContexts: opts.Config.statusContexts(opts.StatusContexts),
+		config:         opts.Config,
+	}
+	return s.detail(ctx, opts.Config.detectors(Detectors()), new(Errors))
+}
+
+// restClient makes JSON API requests to forges without a Go client library
+type restClient struct {
+	client  *http.Client
+	baseURL *url.URL
+	// auth adds credentials to a request
+	auth func(*http.Request)
+}
+
+func newRESTClient(httpClient *http.Client, baseURL string, auth func(*http.Request)) (*restClient, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %q: %v", baseURL, err)
+	}
+	return &restClient{
+		client:  httpClient,
+		baseURL: u,
+		auth:    auth,
+	}, nil
+}
+
+// get requests path, which must already be escaped, relative to the base URL.
+// It decodes the JSON response into v, or reads the raw body if v is a *[]byte.
+func (c *restClient) get(ctx context.Context, path string, query url.Values, v interface{}) (*http.Response, error) {
+	u, err := c.baseURL.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.getURL(ctx, u, query, v)
+}
+
+// getURL is get for an absolute URL, like the next page links some forges return
+func (c *restClient) getURL(ctx context.Context, u *url.URL, query url.Values, v interface{}) (*http.Response, error) {
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.auth != nil {
+		c.auth(req)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return resp, ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return resp, ErrInvalidToken
+	default:
+		return resp, fmt.Errorf("unexpected status from %s: %s", u.Path, resp.Status)
+	}
+	if b, ok := v.(*[]byte); ok {
+		*b, err = io.ReadAll(resp.Body)
+		return resp, err
+	}
+	return resp, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// headerTransport sets a header on every request, like an auth token
+type headerTransport struct {
+	base  http.RoundTripper
+	key   string
+	value string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.key, t.value)
+	return t.base.RoundTrip(req)
+}
+
+// withHeader returns a copy of httpClient that sets a header on every request
+func withHeader(httpClient *http.Client, key, value string) *http.Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c := *httpClient
+	c.Transport = headerTransport{base: base, key: key, value: value}
+	return &c
+}
@@ -0,0 +1,133 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+func TestForgeKind(t *testing.T) {
+	tests := map[string]string{
+		"github.com":        needcla.ForgeGitHub,
+		"www.github.com":    needcla.ForgeGitHub,
+		"GitLab.com":        needcla.ForgeGitLab,
+		"codeberg.org":      needcla.ForgeGitea,
+		"bitbucket.org":     needcla.ForgeBitbucket,
+		"git.example.com":   "",
+		"notgithub.com.net": "",
+	}
+	for host, want := range tests {
+		if got := needcla.ForgeKind(host); got != want {
+			t.Errorf("%s: got %q, wanted %q", host, got, want)
+		}
+	}
+}
+
+func TestForgeURL(t *testing.T) {
+	if got := needcla.ForgeURL(needcla.ForgeGitLab); got != "https://gitlab.com" {
+		t.Errorf("got %q for GitLab", got)
+	}
+	if got := needcla.ForgeURL("svn"); got != "" {
+		t.Errorf("got %q for an unknown forge", got)
+	}
+}
+
+func TestDetailGitea(t *testing.T) {
+	contributing := base64.StdEncoding.EncodeToString([]byte("You must sign the Contributor License Agreement."))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/owner/repo":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case "/api/v1/repos/owner/repo/contents":
+			if r.URL.Query().Get("ref") != "main" {
+				t.Errorf("contents requested for ref %q", r.URL.Query().Get("ref"))
+			}
+			fmt.Fprint(w, `[{"path": ".clabot", "type": "file", "sha": "clabot"}, {"path": ".github", "type": "dir", "sha": "github"}]`)
+		case "/api/v1/repos/owner/repo/contents/.github":
+			fmt.Fprint(w, `[{"path": ".github/CONTRIBUTING.md", "type": "file", "sha": "contributing"}]`)
+		case "/api/v1/repos/owner/repo/git/blobs/contributing":
+			fmt.Fprintf(w, `{"content": %q, "encoding": "base64"}`, contributing)
+		case "/api/v1/repos/owner/repo/commits":
+			fmt.Fprint(w, `[{"sha": "abc123", "commit": {"message": "Initial commit"}}]`)
+		case "/api/v1/repos/owner/repo/pulls":
+			fmt.Fprint(w, `[{"number": 1, "labels": [{"name": "enhancement"}]}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	f, err := needcla.NewForge(srv.Client(), needcla.ForgeGitea, srv.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := needcla.DetailForge(context.Background(), f, "owner", "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.InContributing || !d.BotFile || d.Tag || d.InREADME || d.Result(needcla.DCODetector) {
+		t.Errorf("unexpected details %+v", d)
+	}
+}
+
+func TestDetailBitbucket(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		const repo = "/repositories/workspace/repo"
+		switch r.URL.Path {
+		case repo:
+			fmt.Fprint(w, `{"mainbranch": {"name": "release/1.x"}}`)
+		case repo + "/refs/branches/release/1.x":
+			fmt.Fprint(w, `{"target": {"hash": "abc123"}}`)
+		case repo + "/src/abc123/":
+			if r.URL.Query().Get("page") == "" {
+				fmt.Fprintf(w, `{"values": [{"path": "README.md", "type": "commit_file"}], "next": "%s%s/src/abc123/?page=2"}`, srv.URL, repo)
+				return
+			}
+			fmt.Fprint(w, `{"values": [{"path": ".github", "type": "commit_directory"}]}`)
+		case repo + "/src/abc123/.github/":
+			fmt.Fprint(w, `{"values": [{"path": ".github/workflows", "type": "commit_directory"}]}`)
+		case repo + "/src/abc123/.github/workflows/":
+			fmt.Fprint(w, `{"values": [{"path": ".github/workflows/cla.yml", "type": "commit_file"}]}`)
+		case repo + "/src/abc123/README.md":
+			fmt.Fprint(w, "Nothing to see here")
+		case repo + "/src/abc123/.github/workflows/cla.yml":
+			fmt.Fprint(w, "steps:\n  - uses: cla-assistant/github-action@v2\n")
+		case repo + "/commits/release/1.x":
+			fmt.Fprint(w, `{"values": [{"hash": "abc123", "message": "Initial commit"}]}`)
+		case repo + "/pullrequests":
+			fmt.Fprint(w, `{"values": [{"id": 4}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	f, err := needcla.NewForge(srv.Client(), needcla.ForgeBitbucket, srv.URL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := needcla.DetailForge(context.Background(), f, "workspace", "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.Action || d.InREADME || d.Tag || d.BotFile {
+		t.Errorf("unexpected details %+v", d)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Gitea is a Forge for Gitea and Forgejo instances, like codeberg.org
+type Gitea struct {
+	api *restClient
+}
+
+// NewGitea returns a Gitea for the instance at baseURL, like https://codeberg.org.
+// token is a personal access token and may be empty to check public repositories.
+// If httpClient is nil, http.DefaultClient is used.
+func NewGitea(httpClient *http.Client, baseURL string, token string) (*Gitea, error) {
+	api, err := newRESTClient(httpClient, strings.TrimSuffix(baseURL, "/")+"/api/v1", func(req *http.Request) {
+		if token != "" {
+			req.Header.Set("Authorization", "token "+token)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid gitea url: %v", err)
+	}
+	return &Gitea{api: api}, nil
+}
+
+func (g *Gitea) DefaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	var r struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if _, err := g.api.get(ctx, g.repo(owner, repo), nil, &r); err != nil {
+		return "", fmt.Errorf("%s/%s: %w", owner, repo, err)
+	}
+	return r.DefaultBranch, nil
+}
+
+func (g *Gitea) Source(ctx context.Context, owner, repo, branch string) (Source, error) {
+	return &listedSource{
+		list: func(ctx context.Context, dir string) ([]*Entry, error) {
+			return g.list(ctx, owner, repo, branch, dir)
+		},
+		read: func(ctx context.Context, e *Entry) ([]byte, error) {
+			var b struct {
+				Content  string `json:"content"`
+				Encoding string `json:"encoding"`
+			}
+			if _, err := g.api.get(ctx, g.repo(owner, repo)+"/git/blobs/"+e.SHA, nil, &b); err != nil {
+				return nil, fmt.Errorf("error getting %s blob: %v", e.Path, err)
+			}
+			if b.Encoding != "base64" {
+				return nil, fmt.Errorf("blob is encoded %s, only base64 is supported", b.Encoding)
+			}
+			return base64.StdEncoding.DecodeString(b.Content)
+		},
+	}, nil
+}
+
+// list returns the entries directly inside dir with the contents API, which doesn't page directories
+func (g *Gitea) list(ctx context.Context, owner, repo, branch, dir string) ([]*Entry, error) {
+	p := g.repo(owner, repo) + "/contents"
+	if dir != "" {
+		p += "/" + escapePath(dir)
+	}
+	var contents []struct {
+		Path string `json:"path"`
+		Type string `json:"type"`
+		SHA  string `json:"sha"`
+	}
+	_, err := g.api.get(ctx, p, url.Values{"ref": {branch}}, &contents)
+	if errors.Is(err, ErrNotFound) && dir != "" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s/%s/%s: %v", owner, repo, dir, err)
+	}
+	entries := make([]*Entry, 0, len(contents))
+	for _, c := range contents {
+		entries = append(entries, &Entry{Path: c.Path, Dir: c.Type == "dir", SHA: c.SHA})
+	}
+	return entries, nil
+}
+
+func (g *Gitea) PullRequests(ctx context.Context, owner, repo string) ([]PullRequest, error) {
+	q := url.Values{
+		"state": {"all"},
+		"limit": {"50"},
+	}
+	var pulls []struct {
+		Number int `json:"number"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	if _, err := g.api.get(ctx, g.repo(owner, repo)+"/pulls", q, &pulls); err != nil {
+		return nil, err
+	}
+	prs := make([]PullRequest, 0, len(pulls))
+	for _, pull := range pulls {
+		var labels []string
+		for _, label := range pull.Labels {
+			labels = append(labels, label.Name)
+		}
+		prs = append(prs, PullRequest{Number: pull.Number, Labels: labels})
+	}
+	return prs, nil
+}
+
+func (g *Gitea) Commits(ctx context.Context, owner, repo, branch string, n int) ([]Commit, error) {
+	q := url.Values{
+		"sha":   {branch},
+		"limit": {strconv.Itoa(n)},
+	}
+	var commits []struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Message string `json:"message"`
+		} `json:"commit"`
+	}
+	if _, err := g.api.get(ctx, g.repo(owner, repo)+"/commits", q, &commits); err != nil {
+		return nil, err
+	}
+	out := make([]Commit, 0, len(commits))
+	for _, c := range commits {
+		out = append(out, Commit{SHA: c.SHA, Message: c.Commit.Message})
+	}
+	return out, nil
+}
+
+func (g *Gitea) repo(owner, repo string) string {
+	return "repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+}
@@ -0,0 +1,432 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v43/github"
+)
+
+// GitHub is a Forge for github.com or GitHub Enterprise Server
+type GitHub struct {
+	client *github.Client
+}
+
+// NewGitHub returns a GitHub for the instance at baseURL, like https://github.com.
+// token is a personal access token and may be empty to check public repositories.
+// If httpClient is nil, http.DefaultClient is used.
+func NewGitHub(httpClient *http.Client, baseURL string, token string) (*GitHub, error) {
+	if token != "" {
+		httpClient = withHeader(httpClient, "Authorization", "token "+token)
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid github url %q: %v", baseURL, err)
+	}
+	if baseURL == "" || ForgeKind(u.Host) == ForgeGitHub {
+		return GitHubForge(github.NewClient(httpClient)), nil
+	}
+	base := strings.TrimSuffix(baseURL, "/")
+	client, err := github.NewEnterpriseClient(base+"/api/v3/", base+"/api/uploads/", httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("invalid github url %q: %v", baseURL, err)
+	}
+	return GitHubForge(client), nil
+}
+
+// GitHubForge returns a GitHub that makes requests with client
+func GitHubForge(client *github.Client) *GitHub {
+	return &GitHub{client: client}
+}
+
+func (g *GitHub) DefaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	r, resp, err := g.client.Repositories.Get(ctx, owner, repo)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%s/%s: %w", owner, repo, ErrNotFound)
+	}
+	if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+		return "", ErrInvalidToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s/%s: %w", owner, repo, err)
+	}
+	return r.GetDefaultBranch(), nil
+}
+
+func (g *GitHub) Source(ctx context.Context, owner, repo, branch string) (Source, error) {
+	// pin the branch to a commit so every file is read from the same revision
+	sha, _, err := g.client.Repositories.GetCommitSHA1(ctx, owner, repo, branch, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s/%s branch %s: %v", owner, repo, branch, err)
+	}
+	src, err := newGitHubSource(ctx, g.client, owner, repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	src.commit = sha
+	return src, nil
+}
+
+func (g *GitHub) PullRequests(ctx context.Context, owner, repo string) ([]PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+		State: "all",
+	}
+	ghPRs, _, err := g.client.PullRequests.List(ctx, owner, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	prs := make([]PullRequest, 0, len(ghPRs))
+	for _, pr := range ghPRs {
+		var labels []string
+		for _, label := range pr.Labels {
+			labels = append(labels, label.GetName())
+		}
+		prs = append(prs, PullRequest{
+			Number:   pr.GetNumber(),
+			Labels:   labels,
+			HeadSHA:  pr.GetHead().GetSHA(),
+			External: isExternal(pr.GetAuthorAssociation()),
+		})
+	}
+	return prs, nil
+}
+
+// isExternal reports whether a GitHub author association is for someone outside the repository
+func isExternal(association string) bool {
+	switch association {
+	case "CONTRIBUTOR", "FIRST_TIME_CONTRIBUTOR", "FIRST_TIMER", "NONE":
+		return true
+	}
+	return false
+}
+
+func (g *GitHub) Comments(ctx context.Context, owner, repo string, number, limit int) ([]Comment, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: limit}}
+	ghComments, _, err := g.client.Issues.ListComments(ctx, owner, repo, number, opts)
+	if err != nil {
+		return nil, err
+	}
+	comments := make([]Comment, 0, len(ghComments))
+	for _, c := range ghComments {
+		comments = append(comments, Comment{Author: c.GetUser().GetLogin(), Body: c.GetBody(), URL: c.GetHTMLURL()})
+	}
+	return comments, nil
+}
+
+// branchRule is a rule that applies to a branch from a repository or organization ruleset
+type branchRule struct {
+	Type       string `json:"type"`
+	Parameters struct {
+		RequiredStatusChecks []struct {
+			Context string `json:"context"`
+		} `json:"required_status_checks"`
+	} `json:"parameters"`
+}
+
+func (g *GitHub) RequiredChecks(ctx context.Context, owner, repo, branch string) ([]string, error) {
+	var contexts []string
+	var unknown []string
+
+	// rulesets are readable by anyone who can read the repository
+	req, err := g.client.NewRequest("GET", fmt.Sprintf("repos/%v/%v/rules/branches/%v", owner, repo, url.PathEscape(branch)), nil)
+	if err != nil {
+		return nil, err
+	}
+	var rules []branchRule
+	resp, err := g.client.Do(ctx, req, &rules)
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		// GitHub Enterprise Server versions without rulesets
+	case resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden):
+		unknown = append(unknown, "rulesets")
+	case err != nil:
+		return nil, fmt.Errorf("failed to get %s/%s rules for %s: %v", owner, repo, branch, err)
+	}
+	for _, rule := range rules {
+		if rule.Type != "required_status_checks" {
+			continue
+		}
+		for _, c := range rule.Parameters.RequiredStatusChecks {
+			contexts = append(contexts, c.Context)
+		}
+	}
+
+	// branch protection needs admin access, and GitHub answers 404 rather than 403 to most tokens without it
+	checks, resp, err := g.client.Repositories.GetRequiredStatusChecks(ctx, owner, repo, branch)
+	switch {
+	case err == github.ErrBranchNotProtected:
+	case resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden):
+		unknown = append(unknown, "branch protection")
+	case err != nil:
+		return nil, fmt.Errorf("failed to get %s/%s protection for %s: %v", owner, repo, branch, err)
+	default:
+		contexts = append(contexts, checks.Contexts...)
+		for _, c := range checks.Checks {
+			contexts = append(contexts, c.Context)
+		}
+	}
+
+	if len(unknown) != 0 {
+		return contexts, fmt.Errorf("token can't read %s: %w", strings.Join(unknown, " or "), ErrUnknown)
+	}
+	return contexts, nil
+}
+
+func (g *GitHub) Statuses(ctx context.Context, owner, repo, ref string) ([]Status, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	repoStatuses, _, err := g.client.Repositories.ListStatuses(ctx, owner, repo, ref, opts)
+	if err != nil {
+		return nil, err
+	}
+	var statuses []Status
+	for _, s := range repoStatuses {
+		statuses = append(statuses, Status{
+			Context: s.GetContext(),
+			Creator: s.GetCreator().GetLogin(),
+			State:   s.GetState(),
+			URL:     s.GetTargetURL(),
+		})
+	}
+	runs, _, err := g.client.Checks.ListCheckRunsForRef(ctx, owner, repo, ref, &github.ListCheckRunsOptions{ListOptions: *opts})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range runs.CheckRuns {
+		state := r.GetConclusion()
+		if state == "" {
+			state = r.GetStatus()
+		}
+		statuses = append(statuses, Status{
+			Context: r.GetName(),
+			Creator: r.GetApp().GetSlug(),
+			State:   state,
+			URL:     r.GetDetailsURL(),
+		})
+	}
+	return statuses, nil
+}
+
+func (g *GitHub) Commits(ctx context.Context, owner, repo, branch string, n int) ([]Commit, error) {
+	opts := &github.CommitsListOptions{
+		SHA:         branch,
+		ListOptions: github.ListOptions{PerPage: n},
+	}
+	ghCommits, _, err := g.client.Repositories.ListCommits(ctx, owner, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]Commit, 0, len(ghCommits))
+	for _, c := range ghCommits {
+		commits = append(commits, Commit{SHA: c.GetSHA(), Message: c.GetCommit().GetMessage()})
+	}
+	return commits, nil
+}
+
+// githubSource reads a repository through the GitHub git trees and blobs APIs
+type githubSource struct {
+	branch string
+	commit string
+	repo   string
+	owner  string
+
+	client *github.Client
+
+	tree *github.Tree
+
+	mu       sync.Mutex
+	subtrees map[string]*github.Tree
+}
+
+func newGitHubSource(ctx context.Context, client *github.Client, owner, repo, branch string) (*githubSource, error) {
+	tree, _, err := client.Git.GetTree(ctx, owner, repo, branch, true)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s/%s tree: %v", owner, repo, err)
+	}
+
+	return &githubSource{
+		client: client,
+		branch: branch,
+		repo:   repo,
+		owner:  owner,
+		tree:   tree,
+
+		subtrees: make(map[string]*github.Tree),
+	}, nil
+}
+
+func (g *githubSource) revision() string {
+	return g.commit
+}
+
+func (g *githubSource) Find(ctx context.Context, path string) (*Entry, error) {
+	for _, e := range g.tree.Entries {
+		if e.GetPath() == path {
+			return toEntry(e, path), nil
+		}
+	}
+	if !g.tree.GetTruncated() {
+		return nil, nil
+	}
+	// the recursive tree was too large for one response,
+	// so walk down to the path one directory at a time
+	dir, name := splitPath(path)
+	tree, err := g.subtree(ctx, dir)
+	if tree == nil || err != nil {
+		return nil, err
+	}
+	for _, e := range tree.Entries {
+		if e.GetPath() == name {
+			return toEntry(e, path), nil
+		}
+	}
+	if tree.GetTruncated() {
+		return nil, ErrTruncatedTree
+	}
+	return nil, nil
+}
+
+func (g *githubSource) List(ctx context.Context, dir string) ([]*Entry, error) {
+	if !g.tree.GetTruncated() {
+		var entries []*Entry
+		for _, e := range g.tree.Entries {
+			if d, _ := splitPath(e.GetPath()); d == dir {
+				entries = append(entries, toEntry(e, e.GetPath()))
+			}
+		}
+		return entries, nil
+	}
+	tree, err := g.subtree(ctx, dir)
+	if tree == nil || err != nil {
+		return nil, err
+	}
+	if tree.GetTruncated() {
+		return nil, ErrTruncatedTree
+	}
+	entries := make([]*Entry, 0, len(tree.Entries))
+	for _, e := range tree.Entries {
+		entries = append(entries, toEntry(e, joinPath(dir, e.GetPath())))
+	}
+	return entries, nil
+}
+
+func (g *githubSource) ReadFile(ctx context.Context, e *Entry) ([]byte, error) {
+	b, _, err := g.client.Git.GetBlob(ctx, g.owner, g.repo, e.SHA)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s blob: %v", e.Path, err)
+	}
+	if b.GetEncoding() != "base64" {
+		return nil, fmt.Errorf("blob is encoded %s, only base64 is supported", b.GetEncoding())
+	}
+	return base64.StdEncoding.DecodeString(b.GetContent())
+}
+
+// subtree non-recursively fetches the tree at dir, caching it for other detectors.
+// It returns nil if dir doesn't exist or isn't a directory.
+func (g *githubSource) subtree(ctx context.Context, dir string) (*github.Tree, error) {
+	g.mu.Lock()
+	tree, ok := g.subtrees[dir]
+	g.mu.Unlock()
+	if ok {
+		return tree, nil
+	}
+
+	sha := g.branch
+	if dir != "" {
+		e, err := g.Find(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		if e == nil || !e.Dir {
+			return nil, nil
+		}
+		sha = e.SHA
+	}
+	tree, _, err := g.client.Git.GetTree(ctx, g.owner, g.repo, sha, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s/%s/%s tree: %v", g.owner, g.repo, joinPath(g.branch, dir), err)
+	}
+
+	g.mu.Lock()
+	g.subtrees[dir] = tree
+	g.mu.Unlock()
+	return tree, nil
+}
+
+func (g *githubSource) findCost(path string) Cost {
+	if !g.tree.GetTruncated() {
+		return Cost{}
+	}
+	// one subtree per parent directory, plus the root
+	return Cost{Core: strings.Count(path, "/") + 1}
+}
+
+func (g *githubSource) listCost(dir string) Cost {
+	if !g.tree.GetTruncated() {
+		return Cost{}
+	}
+	return g.findCost(dir).add(Cost{Core: 1})
+}
+
+func (g *githubSource) readCost() Cost {
+	return Cost{Core: 1}
+}
+
+func (g *githubSource) count(dir string) (int, bool) {
+	if g.tree.GetTruncated() {
+		return 0, false
+	}
+	var n int
+	for _, e := range g.tree.Entries {
+		if d, _ := splitPath(e.GetPath()); d == dir {
+			n++
+		}
+	}
+	return n, true
+}
+
+func toEntry(e *github.TreeEntry, path string) *Entry {
+	return &Entry{
+		Path: path,
+		Dir:  e.GetType() == "tree",
+		SHA:  e.GetSHA(),
+	}
+}
+
+func splitPath(path string) (dir, name string) {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return "", path
+}
+
+// escapePath escapes each segment of a repository path for use in a URL path
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func joinPath(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v43/github"
+)
+
+// newTestClient returns a GitHub client that sends every request to mux
+func newTestClient(t *testing.T, mux *http.ServeMux) *github.Client {
+	t.Helper()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+	return client
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestFindTruncatedTree(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("recursive") != "" {
+			writeJSON(w, github.Tree{Truncated: github.Bool(true)})
+			return
+		}
+		writeJSON(w, github.Tree{Entries: []*github.TreeEntry{
+			{Path: github.String("README.md"), Type: github.String("blob"), SHA: github.String("readme")},
+			{Path: github.String(".github"), Type: github.String("tree"), SHA: github.String("dotgithub")},
+		}})
+	})
+	mux.HandleFunc("/repos/o/r/git/trees/dotgithub", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, github.Tree{Entries: []*github.TreeEntry{
+			{Path: github.String("workflows"), Type: github.String("tree"), SHA: github.String("workflows")},
+		}})
+	})
+	mux.HandleFunc("/repos/o/r/git/trees/workflows", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, github.Tree{Entries: []*github.TreeEntry{
+			{Path: github.String("cla.yml"), Type: github.String("blob"), SHA: github.String("cla")},
+		}})
+	})
+
+	ctx := context.Background()
+	src, err := newGitHubSource(ctx, newTestClient(t, mux), "o", "r", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		sha  string
+	}{
+		{"README.md", "readme"},
+		{".github/workflows", "workflows"},
+		{".github/workflows/cla.yml", "cla"},
+		{".clabot", ""},
+		{"docs/CONTRIBUTING.md", ""},
+	}
+	for _, tt := range tests {
+		e, err := src.Find(ctx, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if (e == nil) != (tt.sha == "") {
+			t.Errorf("%s: got %+v, wanted sha %q", tt.path, e, tt.sha)
+			continue
+		}
+		if e != nil && (e.SHA != tt.sha || e.Path != tt.path) {
+			t.Errorf("%s: got %+v, wanted sha %q", tt.path, e, tt.sha)
+		}
+	}
+
+	entries, err := src.List(ctx, ".github/workflows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Path != ".github/workflows/cla.yml" {
+		t.Errorf("unexpected workflows listing: %+v", entries)
+	}
+}
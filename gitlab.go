@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GitLab is a Forge for gitlab.com or a self-hosted GitLab instance.
+// Owners may be nested groups, like "gitlab-org/security".
+type GitLab struct {
+	api *restClient
+}
+
+// NewGitLab returns a GitLab for the instance at baseURL, like https://gitlab.com.
+// token is a personal access token and may be empty to check public projects.
+// If httpClient is nil, http.DefaultClient is used.
+func NewGitLab(httpClient *http.Client, baseURL string, token string) (*GitLab, error) {
+	api, err := newRESTClient(httpClient, strings.TrimSuffix(baseURL, "/")+"/api/v4", func(req *http.Request) {
+		if token != "" {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid gitlab url: %v", err)
+	}
+	return &GitLab{api: api}, nil
+}
+
+func (g *GitLab) DefaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if _, err := g.api.get(ctx, g.project(owner, repo), nil, &project); err != nil {
+		return "", fmt.Errorf("%s/%s: %w", owner, repo, err)
+	}
+	return project.DefaultBranch, nil
+}
+
+func (g *GitLab) Source(ctx context.Context, owner, repo, branch string) (Source, error) {
+	return &listedSource{
+		list: func(ctx context.Context, dir string) ([]*Entry, error) {
+			return g.list(ctx, owner, repo, branch, dir)
+		},
+		read: func(ctx context.Context, e *Entry) ([]byte, error) {
+			var b []byte
+			if _, err := g.api.get(ctx, g.project(owner, repo)+"/repository/blobs/"+e.SHA+"/raw", nil, &b); err != nil {
+				return nil, fmt.Errorf("error getting %s blob: %v", e.Path, err)
+			}
+			return b, nil
+		},
+	}, nil
+}
+
+// list returns the entries directly inside dir, which GitLab pages rather than truncating
+func (g *GitLab) list(ctx context.Context, owner, repo, branch, dir string) ([]*Entry, error) {
+	q := url.Values{
+		"ref":      {branch},
+		"per_page": {"100"},
+	}
+	if dir != "" {
+		q.Set("path", dir)
+	}
+	var out []*Entry
+	for page := "1"; page != ""; {
+		q.Set("page", page)
+		var entries []struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+			Path string `json:"path"`
+		}
+		resp, err := g.api.get(ctx, g.project(owner, repo)+"/repository/tree", q, &entries)
+		if errors.Is(err, ErrNotFound) && dir != "" {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s/%s/%s: %v", owner, repo, dir, err)
+		}
+		for _, e := range entries {
+			out = append(out, &Entry{Path: e.Path, Dir: e.Type == "tree", SHA: e.ID})
+		}
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return out, nil
+}
+
+func (g *GitLab) PullRequests(ctx context.Context, owner, repo string) ([]PullRequest, error) {
+	q := url.Values{
+		"state":    {"all"},
+		"per_page": {"100"},
+	}
+	var mrs []struct {
+		IID    int      `json:"iid"`
+		Labels []string `json:"labels"`
+	}
+	if _, err := g.api.get(ctx, g.project(owner, repo)+"/merge_requests", q, &mrs); err != nil {
+		return nil, err
+	}
+	prs := make([]PullRequest, 0, len(mrs))
+	for _, mr := range mrs {
+		prs = append(prs, PullRequest{Number: mr.IID, Labels: mr.Labels})
+	}
+	return prs, nil
+}
+
+func (g *GitLab) Commits(ctx context.Context, owner, repo, branch string, n int) ([]Commit, error) {
+	q := url.Values{
+		"ref_name": {branch},
+		"per_page": {strconv.Itoa(n)},
+	}
+	var commits []struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+	}
+	if _, err := g.api.get(ctx, g.project(owner, repo)+"/repository/commits", q, &commits); err != nil {
+		return nil, err
+	}
+	out := make([]Commit, 0, len(commits))
+	for _, c := range commits {
+		out = append(out, Commit{SHA: c.ID, Message: c.Message})
+	}
+	return out, nil
+}
+
+// project returns the API path of a project, which GitLab identifies by its URL encoded full path
+func (g *GitLab) project(owner, repo string) string {
+	return "projects/" + url.PathEscape(owner+"/"+repo)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+func newTestGitLab(t *testing.T) *needcla.GitLab {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		const project = "/api/v4/projects/group%2Fsub%2Fproject"
+		switch r.URL.EscapedPath() {
+		case project:
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case project + "/repository/tree":
+			if r.URL.Query().Get("ref") != "main" {
+				t.Errorf("tree requested for ref %q", r.URL.Query().Get("ref"))
+			}
+			if r.URL.Query().Get("recursive") != "" {
+				t.Errorf("expected directories to be listed as they're needed")
+			}
+			if r.URL.Query().Get("path") != "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				fmt.Fprint(w, `[{"id": "readme", "type": "blob", "path": "README.md"}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"id": "ci", "type": "blob", "path": ".gitlab-ci.yml"}]`)
+		case project + "/repository/blobs/readme/raw":
+			fmt.Fprint(w, "# Project\n")
+		case project + "/repository/blobs/ci/raw":
+			fmt.Fprint(w, "stages:\n  - test\n\ncla-check:\n  stage: test\n  script: ./check-cla.sh\n")
+		case project + "/repository/commits":
+			var commits []string
+			for i := 0; i < 5; i++ {
+				commits = append(commits, fmt.Sprintf(`{"id": "c%d", "message": "Fix %d\n\nSigned-off-by: Dev <dev@example.com>\n"}`, i, i))
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(commits, ","))
+		case project + "/merge_requests":
+			fmt.Fprint(w, `[{"iid": 1, "labels": ["bug"]}, {"iid": 2, "labels": ["cla: yes"]}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	gl, err := needcla.NewGitLab(srv.Client(), srv.URL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gl
+}
+
+func TestDetailGitLab(t *testing.T) {
+	gl := newTestGitLab(t)
+	d, err := needcla.DetailForge(context.Background(), gl, "group/sub", "project")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.Tag {
+		t.Errorf("expected merge request label to be detected")
+	}
+	if !d.Action {
+		t.Errorf("expected .gitlab-ci.yml CLA job to be detected")
+	}
+	if !d.Result(needcla.DCODetector) {
+		t.Errorf("expected signed off commits to be detected as a DCO")
+	}
+	if d.InREADME || d.BotFile || d.InContributing {
+		t.Errorf("unexpected details %+v", d)
+	}
+}
+
+func TestDetailGitLabNotFound(t *testing.T) {
+	gl := newTestGitLab(t)
+	_, err := needcla.DetailForge(context.Background(), gl, "group", "missing")
+	if !errors.Is(err, needcla.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
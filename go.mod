@@ -4,6 +4,7 @@ go 1.18
 
 require (
 	github.com/google/go-github/v43 v43.0.0
+	github.com/pelletier/go-toml v1.9.5
 	github.com/peterbourgon/ff/v3 v3.1.0
 	golang.org/x/oauth2 v0.0.0-20210819190943-2bc19b11175f
 )
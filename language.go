@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Language is the phrasing docs written in one language use for CLA policies
+type Language struct {
+	// Code uniquely identifies the language, like "de"
+	Code string
+	// CLA matchers find references to a CLA, like "Contributor-Lizenzvereinbarung"
+	CLA []Matcher
+	// NoCLA matchers find statements that contributors don't need to sign a CLA.
+	// They're also used to keep those statements from counting as references to a CLA.
+	NoCLA []Matcher
+	// Headings matchers find the headings of sections where a CLA policy would be stated, like "Contributing"
+	Headings []Matcher
+}
+
+var languages struct {
+	sync.RWMutex
+	list []Language
+}
+
+// RegisterLanguage adds a language's phrases to those the CONTRIBUTING, README, template, comment and no-CLA checks look for.
+// It panics if l has no Code or CLA matchers, or a language with the same code is already registered.
+func RegisterLanguage(l Language) {
+	languages.Lock()
+	defer languages.Unlock()
+	if l.Code == "" || len(l.CLA) == 0 {
+		panic(fmt.Sprintf("needcla: RegisterLanguage needs a code and CLA matchers, got %q", l.Code))
+	}
+	for _, r := range languages.list {
+		if r.Code == l.Code {
+			panic(fmt.Sprintf("needcla: RegisterLanguage called twice for language %q", l.Code))
+		}
+	}
+	languages.list = append(languages.list, l)
+}
+
+// Languages returns the registered languages in the order they were registered
+func Languages() []Language {
+	languages.RLock()
+	defer languages.RUnlock()
+	list := make([]Language, len(languages.list))
+	copy(list, languages.list)
+	return list
+}
+
+// claMatchers returns every language's CLA matchers, then their no-CLA matchers to veto lines that say one isn't needed
+func claMatchers() matcherSet {
+	var ms matcherSet
+	for _, l := range Languages() {
+		ms = append(ms, l.CLA...)
+	}
+	return append(ms, negate(noCLAMatchers())...)
+}
+
+// noCLAMatchers returns every language's no-CLA matchers
+func noCLAMatchers() matcherSet {
+	var ms matcherSet
+	for _, l := range Languages() {
+		ms = append(ms, l.NoCLA...)
+	}
+	return ms
+}
+
+// isRelevantHeading reports whether a heading path returned by headingAt names a section a CLA policy would be in
+func isRelevantHeading(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, l := range Languages() {
+		for _, m := range l.Headings {
+			if m.Match([]byte(path)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// english is registered first, so its matches are preferred as evidence.
+// "CLA" is matched case-sensitively so words like "clarify" and names like need-cla don't count.
+var english = Language{
+	Code: "en",
+	CLA: []Matcher{
+		mustMatcher("cla-acronym", `\bCLAs?\b`),
+		mustMatcher("cla-phrase", `(?i)\bcontributor\s+license\s+agreements?\b`),
+	},
+	NoCLA: []Matcher{
+		mustMatcher("no-cla-required", `(?i)\bno (?:CLA|contributor license agreement)s? (?:is |are )?(?:required|needed|necessary)`),
+		mustMatcher("there-is-no-cla", `(?i)\bthere is no (?:CLA|contributor license agreement)\b`),
+		mustMatcher("does-not-require-cla", `(?i)\b(?:(?:does|do|will) not|doesn't|don't|won't) (?:require|need) (?:you to sign )?(?:a |an |any )?(?:CLA|contributor license agreement)`),
+		mustMatcher("need-not-sign-cla", `(?i)\b(?:(?:does|do) not|doesn't|don't) (?:have|need) to sign (?:a |an |any )?(?:CLA|contributor license agreement)`),
+		mustMatcher("cla-not-required", `(?i)\b(?:CLA|contributor license agreement)s? (?:is |are )?not (?:required|needed|necessary)`),
+	},
+	Headings: []Matcher{
+		mustMatcher("heading", `(?i)contribut|\blegal\b|licens|agreement|\bCLAs?\b|\bsign`),
+	},
+}
+
+// chinese covers simplified and traditional Chinese, which don't put spaces between words
+var chinese = Language{
+	Code: "zh",
+	CLA: []Matcher{
+		mustMatcher("cla-phrase-zh", `贡献者许可(?:证)?协议|貢獻者(?:許可|授權)協議`),
+	},
+	NoCLA: []Matcher{
+		mustMatcher("no-cla-zh", `(?:无需|无须|不需要|不用|無需|無須|不需)(?:签署|签订|簽署|簽訂)?\s*(?:CLA|贡献者许可(?:证)?协议|貢獻者(?:許可|授權)協議)`),
+	},
+	Headings: []Matcher{
+		mustMatcher("heading-zh", `贡献|貢獻|许可|許可|授权|授權|协议|協議|法律`),
+	},
+}
+
+var japanese = Language{
+	Code: "ja",
+	CLA: []Matcher{
+		mustMatcher("cla-phrase-ja", `コントリビューター?[・\s]?ライセンス[・\s]?(?:契約|アグリーメント|同意書)|貢献者ライセンス契約`),
+	},
+	NoCLA: []Matcher{
+		mustMatcher("no-cla-ja", `(?:CLA|コントリビューター?[・\s]?ライセンス[・\s]?(?:契約|アグリーメント|同意書)|貢献者ライセンス契約)\s*(?:への|の)?(?:署名|サイン|同意)?は(?:不要|必要ありません)`),
+	},
+	Headings: []Matcher{
+		mustMatcher("heading-ja", `コントリビュー|貢献|ライセンス|契約|法的`),
+	},
+}
+
+var german = Language{
+	Code: "de",
+	CLA: []Matcher{
+		mustMatcher("cla-phrase-de", `(?i)\b(?:contributor|mitwirkenden|beitragenden|beitrags)-?\s?lizenzvereinbarung(?:en)?\b`),
+	},
+	NoCLA: []Matcher{
+		mustMatcher("no-cla-de", `(?i)\bkeine?\s+(?:CLA|\S*lizenzvereinbarung)\s+(?:ist\s+|wird\s+)?(?:erforderlich|notwendig|nötig|benötigt|zu\s+unterschreiben|zu\s+unterzeichnen)`),
+		mustMatcher("cla-not-required-de", `(?i)\b(?:CLA|\S*lizenzvereinbarung)\s+(?:ist|wird)\s+nicht\s+(?:erforderlich|notwendig|nötig|benötigt)`),
+	},
+	Headings: []Matcher{
+		mustMatcher("heading-de", `(?i)mitwirk|beitr[aä]g|lizenz|rechtlich`),
+	},
+}
+
+var french = Language{
+	Code: "fr",
+	CLA: []Matcher{
+		mustMatcher("cla-phrase-fr", `(?i)\b(?:accord|contrat)\s+de\s+licence\s+(?:de\s+|du\s+|des\s+)?contribut(?:eur|rice|ion)s?\b`),
+	},
+	NoCLA: []Matcher{
+		mustMatcher("no-cla-fr", `(?i)\baucune?\s+(?:CLA|(?:accord|contrat)\s+de\s+licence)\b`),
+		mustMatcher("cla-not-required-fr", `(?i)\b(?:pas\s+(?:besoin\s+)?de\s+(?:signer\s+(?:de\s+|un\s+)?)?CLA|CLA\s+n['’]est\s+pas\s+(?:requis|nécessaire|obligatoire))`),
+	},
+	Headings: []Matcher{
+		mustMatcher("heading-fr", `(?i)contribu|licence|juridique|mentions\s+légales`),
+	},
+}
+
+func init() {
+	for _, l := range []Language{english, chinese, japanese, german, french} {
+		RegisterLanguage(l)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import "testing"
+
+func TestLanguages(t *testing.T) {
+	tests := []struct {
+		code    string
+		content string
+		// cla is the name of the CLA matcher content should match, or "" if it shouldn't match one
+		cla string
+		// noCLA is the name of the no-CLA matcher content should match, or "" if it shouldn't match one
+		noCLA string
+	}{
+		{"zh", "## 贡献指南\n\n提交 PR 之前，请先签署贡献者许可协议。", "cla-phrase-zh", ""},
+		{"zh", "在我們接受您的貢獻之前，您需要簽署貢獻者授權協議。", "cla-phrase-zh", ""},
+		{"zh", "本项目无需签署贡献者许可协议，直接提交 PR 即可。", "", "no-cla-zh"},
+		{"zh", "不需要签署 CLA。", "", "no-cla-zh"},
+		{"zh", "本项目使用 Apache 许可证。", "", ""},
+		{"ja", "プルリクエストを送る前に、コントリビューターライセンス契約に署名してください。", "cla-phrase-ja", ""},
+		{"ja", "Google のコントリビューター・ライセンス・アグリーメントに同意する必要があります。", "cla-phrase-ja", ""},
+		{"ja", "このプロジェクトでは CLA への署名は不要です。", "", "no-cla-ja"},
+		{"ja", "ライセンスは MIT です。", "", ""},
+		{"de", "Bevor wir Beiträge annehmen, musst du die Contributor-Lizenzvereinbarung unterschreiben.", "cla-phrase-de", ""},
+		{"de", "Alle Mitwirkenden müssen eine Mitwirkenden-Lizenzvereinbarung unterzeichnen.", "cla-phrase-de", ""},
+		{"de", "Für Beiträge ist keine CLA erforderlich.", "", "no-cla-de"},
+		{"de", "Eine Contributor-Lizenzvereinbarung ist nicht erforderlich.", "", "cla-not-required-de"},
+		{"de", "Das Projekt steht unter der Apache-Lizenz.", "", ""},
+		{"fr", "Avant d'accepter votre contribution, vous devez signer l'accord de licence de contributeur.", "cla-phrase-fr", ""},
+		{"fr", "Les contributions sont soumises au contrat de licence du contributeur.", "cla-phrase-fr", ""},
+		{"fr", "Aucun CLA n'est requis pour contribuer.", "", "no-cla-fr"},
+		{"fr", "Pas besoin de signer un CLA.", "", "cla-not-required-fr"},
+		{"fr", "Ce projet est publié sous licence MIT.", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			var cla, noCLA string
+			if ev := claMatchers().match([]byte(test.content)); ev != nil {
+				cla = ev.Matcher
+			}
+			if ev := noCLAMatchers().match([]byte(test.content)); ev != nil {
+				noCLA = ev.Matcher
+			}
+			if cla != test.cla || noCLA != test.noCLA {
+				t.Errorf("%q: expected CLA matcher %q and no-CLA matcher %q, got %q and %q", test.content, test.cla, test.noCLA, cla, noCLA)
+			}
+		})
+	}
+}
+
+func TestLanguageHeadings(t *testing.T) {
+	tests := map[string]bool{
+		"Contributing":           true,
+		"贡献指南":                   true,
+		"コントリビューション":             true,
+		"Mitwirken":              true,
+		"Contribuer > Licence":   true,
+		"Installation > Usage":   false,
+		"インストール":                 false,
+		"":                       false,
+		"Installation > 快速开始":    false,
+		"Mentions légales":       true,
+		"Rechtliche Hinweise":    true,
+		"Changelog > v1.0 > Fix": false,
+	}
+	for path, want := range tests {
+		if got := isRelevantHeading(path); got != want {
+			t.Errorf("%q: expected %v, got %v", path, want, got)
+		}
+	}
+}
+
+func TestPhraseCJK(t *testing.T) {
+	m := Phrase("cla-phrase-zh", "贡献者许可协议")
+	if !m.Match([]byte("请签署贡献者许可协议。")) {
+		t.Error("expected a phrase without word characters to match inside a sentence")
+	}
+}
@@ -0,0 +1,414 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"regexp"
+	"strings"
+)
+
+// document is a file prepared for matching, with everything that isn't prose blanked out
+// so a "CLA" in a code sample, badge or changelog entry doesn't count
+type document struct {
+	// content is the file as read, which snippets are taken from
+	content []byte
+	// masked is content with code, badges, URLs, markup and unrelated sections replaced by spaces,
+	// keeping every newline so offsets and line numbers match content
+	masked []byte
+	// linkable is content with only code, comments and unrelated sections replaced by spaces,
+	// for finding signing links
+	linkable []byte
+	// headings are the document's headings, in order
+	headings []heading
+}
+
+// heading is a Markdown or reStructuredText section heading
+type heading struct {
+	line  int
+	level int
+	text  string
+}
+
+var (
+	// fenceOpen finds the start of a fenced Markdown code block
+	fenceOpen = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	// atxHeading finds "## Heading" lines
+	atxHeading = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	// setextUnderline finds the "====" or "----" lines under Markdown headings
+	setextUnderline = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+	// listItem finds Markdown list items, whose indented lines are prose, not code
+	listItem = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d+[.)])[ \t]`)
+	// mdImage finds images and badges, like ![CLA assistant](https://cla-assistant.io/readme/badge/o/r)
+	mdImage = regexp.MustCompile(`!\[[^\]]*\](?:\([^)]*\)|\[[^\]]*\])?`)
+	// mdLinkTarget finds where a Markdown link points, which isn't prose even though its text is
+	mdLinkTarget = regexp.MustCompile(`\]\([^)]*\)`)
+	// mdLinkDef finds link reference definitions, like [cla]: https://example.com/cla
+	mdLinkDef = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:`)
+	// htmlTag finds HTML tags, whose attributes often hold badge and link URLs
+	htmlTag = regexp.MustCompile(`</?[A-Za-z][^>]*>`)
+	// rstMarkup finds reStructuredText explicit markup, like directives, comments and link targets
+	rstMarkup = regexp.MustCompile(`^([ \t]*)\.\.(?:[ \t]|$)`)
+	// rstAdmonition finds directives whose content is prose, like ".. note::"
+	rstAdmonition = regexp.MustCompile(`(?i)^[ \t]*\.\.[ \t]+(?:admonition|attention|caution|danger|error|hint|important|note|tip|warning)::`)
+	// rstCode finds directives whose content is code, and comments, which don't contain "::"
+	rstCode = regexp.MustCompile(`(?i)^[ \t]*\.\.(?:[ \t]+(?:code|code-block|sourcecode|literalinclude)::|[ \t]*$|[ \t]+[^_\[|][^:]*$)`)
+	// rstLiteral finds reStructuredText inline literals, like ``code``
+	rstLiteral = regexp.MustCompile("``[^`]+``")
+	// headingMarkup finds the markup to strip from heading text
+	headingMarkup = regexp.MustCompile("\\[([^\\]]*)\\]\\([^)]*\\)|[*_`]")
+	// unrelatedHeading finds the headings of sections whose mentions of a CLA say nothing about the project's policy
+	unrelatedHeading = regexp.MustCompile(`(?i)^(?:change ?log|changes|release notes|release history|version history|history|what'?s new|news)\b`)
+)
+
+// plainDocument is a document for content without any markup to parse
+func plainDocument(content []byte) *document {
+	return &document{content: content, masked: content, linkable: content}
+}
+
+// parseDocument parses content as Markdown or reStructuredText, going by path's extension,
+// and as plain text otherwise
+func parseDocument(path string, content []byte) *document {
+	lower := strings.ToLower(path)
+	switch {
+	case isMarkdown(lower):
+		return parseMarkdown(content)
+	case strings.HasSuffix(lower, ".rst") || strings.HasSuffix(lower, ".rest"):
+		return parseRST(content)
+	}
+	return plainDocument(content)
+}
+
+// headingAt returns the path of headings line is under, like "Contributing > Legal", or "" if it isn't under any
+func (d *document) headingAt(line int) string {
+	var path []heading
+	for _, h := range d.headings {
+		if h.line > line {
+			break
+		}
+		for len(path) != 0 && path[len(path)-1].level >= h.level {
+			path = path[:len(path)-1]
+		}
+		path = append(path, h)
+	}
+	texts := make([]string, len(path))
+	for i, h := range path {
+		texts[i] = h.text
+	}
+	return strings.Join(texts, " > ")
+}
+
+// lineSpan is the offsets of a line in a document, without its newline
+type lineSpan struct{ start, end int }
+
+func splitSpans(content []byte) []lineSpan {
+	var spans []lineSpan
+	start := 0
+	for i, c := range content {
+		if c == '\n' {
+			spans = append(spans, lineSpan{start, i})
+			start = i + 1
+		}
+	}
+	return append(spans, lineSpan{start, len(content)})
+}
+
+// documentBuilder masks a document line by line
+type documentBuilder struct {
+	doc   *document
+	spans []lineSpan
+	// skipLevel is the level of the unrelated section being skipped, or 0
+	skipLevel int
+}
+
+func newDocumentBuilder(content []byte) *documentBuilder {
+	return &documentBuilder{
+		doc: &document{
+			content:  content,
+			masked:   append([]byte(nil), content...),
+			linkable: append([]byte(nil), content...),
+		},
+		spans: splitSpans(content),
+	}
+}
+
+func (b *documentBuilder) line(i int) string {
+	return string(b.doc.content[b.spans[i].start:b.spans[i].end])
+}
+
+// mask blanks out the whole of line i
+func (b *documentBuilder) mask(i int) {
+	b.maskRange(i, 0, b.spans[i].end-b.spans[i].start)
+}
+
+// maskRange blanks out bytes start to end of line i, which aren't prose or links, like code
+func (b *documentBuilder) maskRange(i, start, end int) {
+	b.blank(b.doc.linkable, i, start, end)
+	b.blank(b.doc.masked, i, start, end)
+}
+
+// hide blanks out the whole of line i from matching, but not from finding links
+func (b *documentBuilder) hide(i int) {
+	b.hideRange(i, 0, b.spans[i].end-b.spans[i].start)
+}
+
+// hideRange blanks out bytes start to end of line i from matching, but not from finding links, like a badge
+func (b *documentBuilder) hideRange(i, start, end int) {
+	b.blank(b.doc.masked, i, start, end)
+}
+
+// hideAll blanks out every match of re in line i from matching
+func (b *documentBuilder) hideAll(i int, re *regexp.Regexp) {
+	for _, loc := range re.FindAllStringIndex(b.line(i), -1) {
+		b.hideRange(i, loc[0], loc[1])
+	}
+}
+
+func (b *documentBuilder) blank(dst []byte, i, start, end int) {
+	for j := b.spans[i].start + start; j < b.spans[i].start+end; j++ {
+		if dst[j] != '\n' {
+			dst[j] = ' '
+		}
+	}
+}
+
+// addHeading records a heading on line i, starting or ending the skipping of an unrelated section
+func (b *documentBuilder) addHeading(i, level int, text string) {
+	text = strings.TrimSpace(headingMarkup.ReplaceAllString(text, "$1"))
+	if b.skipLevel != 0 && level <= b.skipLevel {
+		b.skipLevel = 0
+	}
+	if b.skipLevel == 0 && unrelatedHeading.MatchString(text) {
+		b.skipLevel = level
+	}
+	b.doc.headings = append(b.doc.headings, heading{line: i + 1, level: level, text: text})
+}
+
+// hideURLs hides the URLs in line i from matching, since they name projects rather than state a policy
+func (b *documentBuilder) hideURLs(i int) {
+	b.hideAll(i, linkMatcher)
+}
+
+// parseMarkdown masks fenced and indented code, inline code, HTML comments and changelog sections,
+// and hides HTML tags, images, link targets and URLs, keeping prose, headings and link text
+func parseMarkdown(content []byte) *document {
+	b := newDocumentBuilder(content)
+	var fence string
+	var inComment, inList bool
+	prevBlank := true
+	for i := range b.spans {
+		text := b.line(i)
+		trimmed := strings.TrimSpace(text)
+		blank := trimmed == ""
+
+		switch {
+		case fence != "":
+			b.mask(i)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		case inComment:
+			end := strings.Index(text, "-->")
+			if end < 0 {
+				b.mask(i)
+				continue
+			}
+			b.maskRange(i, 0, end+3)
+			inComment = false
+		}
+		if m := fenceOpen.FindStringSubmatch(text); m != nil {
+			fence = m[1]
+			b.mask(i)
+			continue
+		}
+		indented := strings.HasPrefix(text, "    ") || strings.HasPrefix(text, "\t")
+		// indented code starts after a blank line, and prevBlank stays set while it lasts
+		if !blank && indented && !inList && prevBlank {
+			b.mask(i)
+			continue
+		}
+		prevBlank = blank
+
+		if m := atxHeading.FindStringSubmatch(text); m != nil {
+			b.addHeading(i, len(m[1]), m[2])
+		} else if !blank && i+1 < len(b.spans) && setextUnderline.MatchString(b.line(i+1)) && !listItem.MatchString(text) {
+			level := 1
+			if strings.Contains(b.line(i+1), "-") {
+				level = 2
+			}
+			b.addHeading(i, level, trimmed)
+		}
+		if b.skipLevel != 0 {
+			b.mask(i)
+			continue
+		}
+
+		switch {
+		case listItem.MatchString(text):
+			inList = true
+		case !blank && !indented:
+			inList = false
+		}
+		if mdLinkDef.MatchString(text) {
+			b.hide(i)
+			continue
+		}
+		b.maskMarkdownInline(i)
+		if start := strings.LastIndex(b.line(i), "<!--"); start >= 0 && !strings.Contains(b.line(i)[start:], "-->") {
+			b.maskRange(i, start, len(text))
+			inComment = true
+		}
+	}
+	return b.doc
+}
+
+// maskMarkdownInline masks the code spans and comments in line i, and hides its tags, images, link targets and URLs
+func (b *documentBuilder) maskMarkdownInline(i int) {
+	text := b.line(i)
+	// code spans open and close with the same number of backticks
+	for j := 0; j < len(text); {
+		if text[j] != '`' {
+			j++
+			continue
+		}
+		n := j
+		for n < len(text) && text[n] == '`' {
+			n++
+		}
+		run := text[j:n]
+		end := -1
+		for k := n; k < len(text); {
+			next := strings.Index(text[k:], run)
+			if next < 0 {
+				break
+			}
+			k += next
+			m := k + len(run)
+			if m == len(text) || text[m] != '`' {
+				end = m
+				break
+			}
+			for k < len(text) && text[k] == '`' {
+				k++
+			}
+		}
+		if end < 0 {
+			j = n
+			continue
+		}
+		b.maskRange(i, j, end)
+		j = end
+	}
+	for start := strings.Index(text, "<!--"); start >= 0; start = strings.Index(text, "<!--") {
+		end := strings.Index(text[start:], "-->")
+		if end < 0 {
+			break
+		}
+		b.maskRange(i, start, start+end+3)
+		text = text[:start] + strings.Repeat(" ", end+3) + text[start+end+3:]
+	}
+	b.hideAll(i, mdImage)
+	b.hideAll(i, mdLinkTarget)
+	b.hideAll(i, htmlTag)
+	b.hideURLs(i)
+}
+
+// rstAdornments are the characters reStructuredText headings are under- and overlined with
+const rstAdornments = "=-~^\"'`#*+:.<>_"
+
+// isRSTUnderline reports whether line is a reStructuredText heading's under- or overline, like "====="
+func isRSTUnderline(line string) bool {
+	line = strings.TrimRight(line, " \t")
+	if len(line) < 3 || !strings.ContainsRune(rstAdornments, rune(line[0])) {
+		return false
+	}
+	return strings.Trim(line, line[:1]) == ""
+}
+
+// parseRST masks literal blocks, code directives, comments, inline literals and changelog sections,
+// and hides other directives and link targets, keeping prose, headings and the content of admonitions like ".. note::"
+func parseRST(content []byte) *document {
+	b := newDocumentBuilder(content)
+	levels := make(map[string]int)
+	// blockIndent is the indentation a masked block's lines must exceed, or -1 if no block is being masked
+	blockIndent := -1
+	// blockCode is whether the block is code or a comment, rather than markup like an image directive
+	blockCode := false
+	// literalIndent is the indentation of a paragraph ending in "::", whose indented block is a literal block,
+	// or -1 if there isn't one
+	literalIndent := -1
+	for i := range b.spans {
+		text := b.line(i)
+		trimmed := strings.TrimSpace(text)
+		indent := len(text) - len(strings.TrimLeft(text, " \t"))
+		if trimmed == "" {
+			continue
+		}
+
+		if literalIndent >= 0 {
+			if indent > literalIndent && blockIndent < 0 {
+				blockIndent, blockCode = literalIndent, true
+			}
+			literalIndent = -1
+		}
+		if blockIndent >= 0 {
+			if indent > blockIndent {
+				if blockCode {
+					b.mask(i)
+				} else {
+					b.hide(i)
+				}
+				continue
+			}
+			blockIndent = -1
+		}
+
+		if m := rstMarkup.FindStringSubmatch(text); m != nil {
+			switch {
+			case rstAdmonition.MatchString(text):
+				b.hideRange(i, 0, strings.Index(text, "::")+2)
+			case rstCode.MatchString(text):
+				b.mask(i)
+				blockIndent, blockCode = len(m[1]), true
+			default:
+				b.hide(i)
+				blockIndent, blockCode = len(m[1]), false
+			}
+			continue
+		}
+
+		if isRSTUnderline(text) {
+			// under- and overlines were handled with the heading text
+			b.hide(i)
+			continue
+		}
+		if i+1 < len(b.spans) && isRSTUnderline(b.line(i+1)) && indent == 0 {
+			style := b.line(i + 1)[:1]
+			if i > 0 && isRSTUnderline(b.line(i-1)) {
+				style += "/"
+			}
+			if _, ok := levels[style]; !ok {
+				levels[style] = len(levels) + 1
+			}
+			b.addHeading(i, levels[style], trimmed)
+		}
+		if b.skipLevel != 0 {
+			b.mask(i)
+			continue
+		}
+
+		if strings.HasSuffix(trimmed, "::") {
+			literalIndent = indent
+		}
+		for _, loc := range rstLiteral.FindAllStringIndex(text, -1) {
+			b.maskRange(i, loc[0], loc[1])
+		}
+		b.hideURLs(i)
+	}
+	return b.doc
+}
@@ -0,0 +1,156 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		heading string
+	}{
+		{
+			name:    "fenced code",
+			content: "# Tool\n\n```sh\nCLA=1 make\n```\n\n~~~\nCLA\n~~~\n",
+		},
+		{
+			name:    "indented code",
+			content: "Run it:\n\n    export CLA=1\n\nThen build.",
+		},
+		{
+			name:    "indented list item",
+			content: "- Before sending a PR:\n\n    Sign the CLA first.",
+			line:    3,
+		},
+		{
+			name:    "inline code",
+			content: "Set `CLA` or ``the `CLA` var`` to skip the check.",
+		},
+		{
+			name: "badges",
+			content: "[![CLA assistant](https://cla-assistant.io/readme/badge/o/r)](https://cla-assistant.io/o/r)\n" +
+				`<img alt="CLA" src="https://example.com/CLA.svg">`,
+		},
+		{
+			name:    "link target",
+			content: "We build on [a parser](https://github.com/example/CLA-parser).",
+		},
+		{
+			name:    "link text",
+			content: "Please sign the [CLA](https://cla-assistant.io/o/r).",
+			line:    1,
+		},
+		{
+			name:    "comment",
+			content: "Hello <!-- no CLA yet\nstill no CLA --> world",
+		},
+		{
+			name: "changelog",
+			content: "# Project\n\n## Changelog\n\n### v1.2.0\n\n- Fixed CLA parsing in the importer\n\n" +
+				"## License\n\nMIT, and contributors sign our CLA.",
+			line:    11,
+			heading: "Project > License",
+		},
+		{
+			name:    "relevant heading preferred",
+			content: "# Project\n\nCLA stands for something else here.\n\nContributing\n------------\n\nSign the CLA.",
+			line:    8,
+			heading: "Project > Contributing",
+		},
+		{
+			name:    "heading counts as prose",
+			content: "Intro\n\n## Contributor License Agreement\n\nSee below.",
+			line:    3,
+			heading: "Contributor License Agreement",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ev := claMatchers().matchFile("README.md", []byte(test.content))
+			if test.line == 0 {
+				if ev != nil {
+					t.Fatalf("expected no match, got %+v", ev)
+				}
+				return
+			}
+			if ev == nil {
+				t.Fatal("expected a match")
+			}
+			if ev.Line != test.line || ev.Heading != test.heading {
+				t.Errorf("expected line %d under %q, got %+v", test.line, test.heading, ev)
+			}
+		})
+	}
+}
+
+func TestMarkdownKeepsOffsets(t *testing.T) {
+	content := "# CLA\n\n```\ncode\n```\n`x` ![b](u) [t](https://example.com)\n"
+	doc := parseMarkdown([]byte(content))
+	if len(doc.masked) != len(content) || strings.Count(string(doc.masked), "\n") != strings.Count(content, "\n") {
+		t.Errorf("expected masking to keep offsets, got %q", doc.masked)
+	}
+	if !strings.HasPrefix(string(doc.masked), "# CLA\n") {
+		t.Errorf("expected the heading to be kept, got %q", doc.masked)
+	}
+}
+
+func TestRSTMatch(t *testing.T) {
+	content := `=======
+Project
+=======
+
+Usage
+-----
+
+Run::
+
+    CLA=1 make
+
+.. code-block:: sh
+
+   export CLA=1
+
+.. image:: https://example.com/CLA.svg
+   :alt: CLA
+
+Set ` + "``CLA``" + ` to skip.
+
+Contributing
+------------
+
+.. note:: Contributors sign the Contributor License Agreement.
+`
+	ev := claMatchers().matchFile("docs/CONTRIBUTING.rst", []byte(content))
+	if ev == nil {
+		t.Fatal("expected a match")
+	}
+	if ev.Line != 24 || ev.Matcher != "cla-phrase" || ev.Heading != "Project > Contributing" {
+		t.Errorf("expected the note under Contributing, got %+v", ev)
+	}
+}
+
+func TestPlainDocumentUnparsed(t *testing.T) {
+	ev := claMatchers().matchFile("README", []byte("```\nSign the CLA\n```"))
+	if ev == nil || ev.Line != 2 || ev.Heading != "" {
+		t.Errorf("expected plain text to be matched as is, got %+v", ev)
+	}
+}
+
+func TestDocumentSigningLinks(t *testing.T) {
+	content := "[![CLA assistant](https://cla-assistant.io/readme/badge/o/r)](https://cla-assistant.io/o/r)\n\n" +
+		"Link yours like `cla-assistant.io/owner/repo`:\n\n```\nhttps://cla-assistant.io/example/repo\n```\n"
+	links := documentSigningLinks(parseMarkdown([]byte(content)))
+	if len(links) != 1 || links[0].url != "https://cla-assistant.io/o/r" || links[0].line != 1 {
+		t.Errorf("expected only the badge's signing link, got %+v", links)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Matcher is a named, precompiled pattern that detectors look for in file contents
+type Matcher struct {
+	// Name identifies the matcher in evidence, like "cla-acronym"
+	Name string
+	// Negative matchers veto the lines they match, so "we don't require a CLA" doesn't count as a reference to one
+	Negative bool
+
+	re *regexp.Regexp
+}
+
+// NewMatcher compiles pattern, a regular expression, into a Matcher
+func NewMatcher(name, pattern string) (Matcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Matcher{}, fmt.Errorf(`error compiling matcher %q: %v`, name, err)
+	}
+	return Matcher{Name: name, re: re}, nil
+}
+
+// Phrase returns a Matcher for phrase as whole words, ignoring case, where spaces match any run of whitespace
+func Phrase(name, phrase string) Matcher {
+	phrase = strings.TrimSpace(phrase)
+	words := strings.Fields(phrase)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	if len(words) == 0 {
+		return Matcher{Name: name}
+	}
+	pattern := strings.Join(words, `\s+`)
+	// word boundaries only hold next to word characters, so a phrase can end in punctuation like "(CLA)"
+	if first, _ := utf8.DecodeRuneInString(phrase); isWordChar(first) {
+		pattern = `\b` + pattern
+	}
+	if last, _ := utf8.DecodeLastRuneInString(phrase); isWordChar(last) {
+		pattern += `\b`
+	}
+	return mustMatcher(name, `(?i)`+pattern)
+}
+
+// isWordChar reports whether \b treats r as part of a word, which is only true of ASCII letters, digits and _
+func isWordChar(r rune) bool {
+	return r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// mustMatcher is NewMatcher for built-in patterns, panicking if pattern doesn't compile
+func mustMatcher(name, pattern string) Matcher {
+	m, err := NewMatcher(name, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Match reports whether content matches m
+func (m Matcher) Match(content []byte) bool {
+	return m.re != nil && m.re.Match(content)
+}
+
+func (m Matcher) String() string {
+	if m.re == nil {
+		return m.Name
+	}
+	return fmt.Sprintf("%s: %s", m.Name, m.re)
+}
+
+// matcherSet is an ordered set of matchers, where earlier matchers are preferred as evidence
+type matcherSet []Matcher
+
+// negate returns copies of ms that veto the lines they match
+func negate(ms matcherSet) matcherSet {
+	negated := make(matcherSet, len(ms))
+	for i, m := range ms {
+		m.Negative = true
+		negated[i] = m
+	}
+	return negated
+}
+
+// match is matchDocument for content without any markup to parse
+func (ms matcherSet) match(content []byte) *Evidence {
+	return ms.matchDocument(plainDocument(content))
+}
+
+// matchFile is matchDocument for content read from the file at path, parsed as Markdown or reStructuredText
+func (ms matcherSet) matchFile(path string, content []byte) *Evidence {
+	return ms.matchDocument(parseDocument(path, content))
+}
+
+// matchDocument returns evidence locating the prose in doc matched by the first positive matcher that matches,
+// skipping lines a negative matcher matches, or nil if none match.
+// A match under a relevant heading, like "Contributing", is preferred over one that isn't.
+func (ms matcherSet) matchDocument(doc *document) *Evidence {
+	var first *Evidence
+	for _, m := range ms {
+		if m.Negative || m.re == nil {
+			continue
+		}
+		for _, loc := range m.re.FindAllIndex(doc.masked, -1) {
+			start := bytes.LastIndexByte(doc.masked[:loc[0]], '\n') + 1
+			end := bytes.IndexByte(doc.masked[loc[0]:], '\n')
+			if end < 0 {
+				end = len(doc.masked)
+			} else {
+				end += loc[0]
+			}
+			if ms.vetoes(doc.masked[start:end]) {
+				continue
+			}
+			snippet := truncateSnippet(strings.TrimSpace(string(doc.content[start:end])))
+			line := bytes.Count(doc.masked[:loc[0]], []byte("\n")) + 1
+			ev := &Evidence{Line: line, Snippet: snippet, Matcher: m.Name, Heading: doc.headingAt(line)}
+			if isRelevantHeading(ev.Heading) {
+				return ev
+			}
+			if first == nil {
+				first = ev
+			}
+		}
+	}
+	return first
+}
+
+// vetoes reports whether a negative matcher matches line
+func (ms matcherSet) vetoes(line []byte) bool {
+	for _, m := range ms {
+		if m.Negative && m.Match(line) {
+			return true
+		}
+	}
+	return false
+}
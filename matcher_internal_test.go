@@ -0,0 +1,197 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMatcherSetMatch(t *testing.T) {
+	content := []byte("# Contributing\n\nFirst,   sign our Contributor License Agreement.  \nThanks!")
+	ev := claMatchers().match(content)
+	if ev == nil || ev.Line != 3 || ev.Snippet != "First,   sign our Contributor License Agreement." || ev.Matcher != "cla-phrase" {
+		t.Errorf("unexpected evidence %+v", ev)
+	}
+
+	if ev := claMatchers().match([]byte("no agreement here")); ev != nil {
+		t.Errorf("expected no evidence, got %+v", ev)
+	}
+}
+
+func TestMatcherSetMatchTruncatesRunes(t *testing.T) {
+	// the 200 byte cut falls in the middle of a 3 byte character
+	line := "Please sign the Contributor License Agreement " + strings.Repeat("贡献", 40)
+	ev := claMatchers().match([]byte(line))
+	if ev == nil {
+		t.Fatal("expected evidence")
+	}
+	if len(ev.Snippet) > maxSnippet || !utf8.ValidString(ev.Snippet) || !strings.HasPrefix(line, ev.Snippet) {
+		t.Errorf("expected a valid UTF-8 prefix of at most %d bytes, got %q", maxSnippet, ev.Snippet)
+	}
+	if len(ev.Snippet) != 199 {
+		t.Errorf("expected the snippet to end at the last whole character, got %d bytes", len(ev.Snippet))
+	}
+}
+
+func TestCLAMatchers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		matcher string
+		line    int
+	}{
+		{
+			name: "google",
+			content: "# How to Contribute\n\nWe'd love to accept your patches and contributions to this project.\n\n" +
+				"## Contributor License Agreement\n\nContributions to this project must be accompanied by a Contributor License\n" +
+				"Agreement (CLA). You (or your employer) retain the copyright to your contribution;",
+			matcher: "cla-acronym",
+			line:    8,
+		},
+		{
+			name: "microsoft",
+			content: "This project welcomes contributions and suggestions.  Most contributions require you to agree to a\n" +
+				"Contributor License Agreement (CLA) declaring that you have the right to, and actually do, grant us\n" +
+				"the rights to use your contribution. For details, visit https://cla.opensource.microsoft.com.",
+			matcher: "cla-acronym",
+			line:    2,
+		},
+		{
+			name: "cncf",
+			content: "## Contributing\n\nThis project requires contributors to sign the CNCF\n" +
+				"[Contributor License Agreement](https://github.com/cncf/cla) before their pull requests can be merged.",
+			matcher: "cla-phrase",
+			line:    4,
+		},
+		{
+			name:    "phrase across lines",
+			content: "Please sign the Individual Contributor\nLicense Agreement first.",
+			matcher: "cla-phrase",
+			line:    1,
+		},
+		{
+			name:    "plural",
+			content: "We accept contributions under Contributor License Agreements.",
+			matcher: "cla-phrase",
+			line:    1,
+		},
+		{
+			name: "cla-assistant badge",
+			content: "# Project\n\n[![CLA assistant](https://cla-assistant.io/readme/badge/org/project)]" +
+				"(https://cla-assistant.io/org/project)",
+			matcher: "cla-acronym",
+			line:    3,
+		},
+		{
+			name:    "negative line skipped",
+			content: "This project does not require a CLA.\n\nForks that do need a CLA should say so.",
+			matcher: "cla-acronym",
+			line:    3,
+		},
+		{
+			name:    "only negative",
+			content: "# Contributing\n\nThis project doesn't require you to sign a Contributor License Agreement.",
+		},
+		{
+			name:    "project name",
+			content: "Install with `go install github.com/progressive-insurance/need-cla/cmd/need-cla@latest`.",
+		},
+		{
+			name:    "lowercase words",
+			content: "Please clarify the issue, and check the cla-assistant.io docs.",
+		},
+		{
+			name:    "longer acronym",
+			content: "We use CLAHE for contrast-limited adaptive histogram equalization.",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ev := claMatchers().match([]byte(test.content))
+			if test.matcher == "" {
+				if ev != nil {
+					t.Fatalf("expected no match, got %+v", ev)
+				}
+				return
+			}
+			if ev == nil {
+				t.Fatal("expected a match")
+			}
+			if ev.Matcher != test.matcher || ev.Line != test.line {
+				t.Errorf("expected %s on line %d, got %+v", test.matcher, test.line, ev)
+			}
+		})
+	}
+}
+
+func TestNoCLAMatchers(t *testing.T) {
+	tests := map[string]string{
+		"No CLA is required to contribute.":                               "no-cla-required",
+		"There is no CLA to sign.":                                        "there-is-no-cla",
+		"We won't require you to sign a Contributor License Agreement.":   "does-not-require-cla",
+		"You don't have to sign a CLA.":                                   "need-not-sign-cla",
+		"A Contributor License Agreement is not required for this repo.":  "cla-not-required",
+		"Contributions require a CLA.":                                    "",
+		"There's no need to open an issue before sending a pull request.": "",
+	}
+	for content, want := range tests {
+		var got string
+		if ev := noCLAMatchers().match([]byte(content)); ev != nil {
+			got = ev.Matcher
+		}
+		if got != want {
+			t.Errorf("%q: expected %q, got %q", content, want, got)
+		}
+	}
+}
+
+func TestPRLabelMatcher(t *testing.T) {
+	tests := map[string]bool{
+		"cla: yes":        true,
+		"cla: no":         true,
+		"CLA: Yes":        true,
+		"cla:yes":         true,
+		"cla/signed":      true,
+		"cla: not-signed": true,
+		"cla: y":          false,
+		"cla: nope":       false,
+		"cla: soon":       false,
+		"clarification":   false,
+		"needs cla: yes":  false,
+	}
+	for label, want := range tests {
+		if got := prLabelMatcher.Match([]byte(label)); got != want {
+			t.Errorf("%q: expected %v, got %v", label, want, got)
+		}
+	}
+}
+
+func TestPhrase(t *testing.T) {
+	m := Phrase("dco", "Developer Certificate of Origin (DCO)")
+	if !m.Match([]byte("sign off under the developer  certificate\nof origin (dco)")) {
+		t.Error("expected the phrase to match ignoring case and whitespace")
+	}
+	if m.Match([]byte("Developer Certificate of Origins")) {
+		t.Error("expected the phrase to match whole words only")
+	}
+}
+
+func TestNewMatcher(t *testing.T) {
+	if _, err := NewMatcher("broken", `(`); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+	m, err := NewMatcher("sign", `(?i)\bsign\b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Match([]byte("Please SIGN here")) || m.Match([]byte("signature")) {
+		t.Errorf("unexpected matches for %v", m)
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// requiredCLAMatcher finds CLA-looking required status check contexts that aren't in the configured contexts,
+// like "cla-check" or "CLAssistant", without matching words that contain "cla" like "declaration"
+var requiredCLAMatcher = regexp.MustCompile(`(?i)(?:^|[^a-z])cla(?:[^a-z]|$)|easycla|clas?sistant`)
+
+// requiresCLACheck looks for a CLA status check among the checks the branch requires before merging.
+// It's unknown, rather than an error, if the token can't read the branch's protection and no CLA check was found.
+func (s *Snapshot) requiresCLACheck(ctx context.Context) (bool, error) {
+	lister, ok := s.forge.(RequiredCheckLister)
+	if !ok {
+		return false, nil
+	}
+	contexts := s.statusContexts
+	if contexts == nil {
+		contexts = DefaultStatusContexts
+	}
+	required, err := lister.RequiredChecks(ctx, s.owner, s.repo, s.branch)
+	for _, c := range required {
+		if isCLAStatus(Status{Context: c}, contexts) || requiredCLAMatcher.MatchString(c) {
+			s.AddEvidence(Evidence{Status: c, Snippet: fmt.Sprintf("%s requires %q before merging", s.branch, c)})
+			return true, nil
+		}
+	}
+	if err != nil && !errors.Is(err, ErrUnknown) {
+		return false, fmt.Errorf("error getting %s/%s required checks: %v", s.owner, s.repo, err)
+	}
+	return false, err
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-github/v43/github"
+)
+
+func TestRequiresCLACheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		rules      interface{}
+		protection func(w http.ResponseWriter)
+		found      bool
+		unknown    bool
+	}{
+		{
+			name: "ruleset",
+			rules: []map[string]interface{}{
+				{"type": "deletion"},
+				{"type": "required_status_checks", "parameters": map[string]interface{}{
+					"required_status_checks": []map[string]interface{}{{"context": "license/cla"}},
+				}},
+			},
+			protection: func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			found:      true,
+		},
+		{
+			name:  "branch protection",
+			rules: []interface{}{},
+			protection: func(w http.ResponseWriter) {
+				writeJSON(w, github.RequiredStatusChecks{Contexts: []string{"ci/build", "cla-check"}})
+			},
+			found: true,
+		},
+		{
+			name:  "not protected",
+			rules: []interface{}{},
+			protection: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusNotFound)
+				writeJSON(w, map[string]string{"message": "Branch not protected"})
+			},
+		},
+		{
+			name:  "no permission",
+			rules: []interface{}{},
+			protection: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusNotFound)
+				writeJSON(w, map[string]string{"message": "Not Found"})
+			},
+			unknown: true,
+		},
+		{
+			name:  "other checks",
+			rules: []interface{}{},
+			protection: func(w http.ResponseWriter) {
+				writeJSON(w, github.RequiredStatusChecks{Checks: []*github.RequiredStatusCheck{{Context: "declaration-lint"}}})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/o/r/rules/branches/main", func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, tt.rules)
+			})
+			mux.HandleFunc("/repos/o/r/branches/main/protection/required_status_checks", func(w http.ResponseWriter, r *http.Request) {
+				tt.protection(w)
+			})
+			s := &Snapshot{
+				owner:  "o",
+				repo:   "r",
+				branch: "main",
+				forge:  GitHubForge(newTestClient(t, mux)),
+				src:    NewFSSource(fstest.MapFS{}),
+			}
+			var detectors []Detector
+			for _, det := range Detectors() {
+				if det.Name() == RequiredCheckDetector {
+					detectors = append(detectors, det)
+				}
+			}
+			d, err := s.detail(context.Background(), detectors, new(Errors))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d.Result(RequiredCheckDetector) != tt.found || d.IsUnknown(RequiredCheckDetector) != tt.unknown {
+				t.Errorf("got found %v and unknown %v, wanted %v and %v", d.Result(RequiredCheckDetector), d.Unknown, tt.found, tt.unknown)
+			}
+			if tt.found && (len(d.Evidence) != 1 || d.Evidence[0].Status == "") {
+				t.Errorf("expected evidence of the required check, got %+v", d.Evidence)
+			}
+		})
+	}
+}
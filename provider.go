@@ -0,0 +1,199 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Providers are the CLA services evidence can point to
+const (
+	// ProviderCLAAssistant is SAP's cla-assistant.io, or its GitHub Action
+	ProviderCLAAssistant = "cla-assistant.io"
+	// ProviderGoogle is Google's CLA, whose bot labels PRs "cla: yes"
+	ProviderGoogle = "Google CLA"
+	// ProviderEasyCLA is the Linux Foundation's CLA service, used by CNCF and other LF projects
+	ProviderEasyCLA = "EasyCLA"
+	// ProviderApache is the Apache Software Foundation's Individual Contributor License Agreement
+	ProviderApache = "Apache ICLA"
+	// ProviderMicrosoft is the Microsoft CLA bot
+	ProviderMicrosoft = "Microsoft CLA bot"
+	// ProviderCLABot is cla-bot, configured with a .clabot file
+	ProviderCLABot = "cla-bot"
+	// ProviderCustom is a CLA the project collects itself, found from a signing link next to a mention of a CLA
+	ProviderCustom = "custom"
+)
+
+// linkMatcher finds URLs, and links to known CLA services that leave off the scheme
+var linkMatcher = regexp.MustCompile(`(?i)https?://[^\s)"'<>\]]+|\b(?:cla-assistant\.io|cla\.developers\.google\.com|cla\.opensource\.microsoft\.com)/[^\s)"'<>\]]*`)
+
+// signingLineMatcher finds lines where a link to an unknown host may be a CLA signing page,
+// including the path-to-document input of the CLA Assistant Lite action.
+// "CLA" is matched case-sensitively and not next to - or /, so names like need-cla don't count.
+var signingLineMatcher = regexp.MustCompile(`(?:^|[^\w/-])CLAs?(?:$|[^\w/-])|(?i)contributor license agreement|path-to-document`)
+
+// customLinkMatcher finds links to unknown hosts that look like a CLA page
+var customLinkMatcher = regexp.MustCompile(`(?i)cla|agreement|sign`)
+
+// imageMatcher finds badge and image links, which don't lead anywhere to sign
+var imageMatcher = regexp.MustCompile(`(?i)shields\.io|/badge|\.(?:svg|png|gif|jpe?g)(?:$|\?)`)
+
+// signingLink is a link to a page where contributors sign a CLA
+type signingLink struct {
+	provider string
+	url      string
+	line     int
+	snippet  string
+}
+
+// signingProvider returns the CLA service hosting u, ProviderCustom if u is on an unknown host
+// and custom is true, or "" if u isn't a signing page
+func signingProvider(u *url.URL, custom bool) string {
+	host := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www."))
+	path := strings.ToLower(u.Path)
+	switch {
+	case host == "cla-assistant.io":
+		if strings.HasPrefix(path, "/readme/") || strings.Count(strings.Trim(path, "/"), "/") != 1 {
+			return ""
+		}
+		return ProviderCLAAssistant
+	case host == "cla.developers.google.com":
+		return ProviderGoogle
+	case host == "cla.opensource.microsoft.com":
+		return ProviderMicrosoft
+	case strings.Contains(host, "easycla") || host == "lfcla.com" || strings.HasSuffix(host, ".lfcla.com"):
+		return ProviderEasyCLA
+	case host == "apache.org" && (strings.Contains(path, "contributor-agreements") || strings.Contains(path, "icla")):
+		return ProviderApache
+	case custom && customLinkMatcher.MatchString(u.Path) && !imageMatcher.MatchString(u.String()) &&
+		!strings.HasSuffix(host, "wikipedia.org"):
+		return ProviderCustom
+	}
+	return ""
+}
+
+// signingLinks returns the CLA signing links in content, in order
+func signingLinks(content []byte) []signingLink {
+	return documentSigningLinks(plainDocument(content))
+}
+
+// documentSigningLinks returns the CLA signing links in doc outside of code, in order
+func documentSigningLinks(doc *document) []signingLink {
+	var links []signingLink
+	original := strings.Split(string(doc.content), "\n")
+	for i, line := range strings.Split(string(doc.linkable), "\n") {
+		custom := signingLineMatcher.MatchString(line)
+		for _, raw := range linkMatcher.FindAllString(line, -1) {
+			raw = strings.TrimRight(raw, ".,;:!?*_`")
+			if !strings.Contains(raw, "://") {
+				raw = "https://" + raw
+			}
+			u, err := url.Parse(raw)
+			if err != nil || u.Host == "" {
+				continue
+			}
+			provider := signingProvider(u, custom)
+			if provider == "" {
+				continue
+			}
+			links = append(links, signingLink{provider: provider, url: raw, line: i + 1, snippet: truncateSnippet(strings.TrimSpace(original[i]))})
+		}
+	}
+	return links
+}
+
+// linksToSigningPage looks for CLA signing links in README, CONTRIBUTING and CI workflows,
+// recording each link and the service it belongs to as evidence
+func (s *Snapshot) linksToSigningPage(ctx context.Context) (bool, error) {
+	var files []*Entry
+	var errs []string
+	for _, name := range []string{contributingDoc, readmeDoc} {
+		e, _, err := s.readDoc(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("* %s: %v", name, err))
+			continue
+		}
+		if e != nil {
+			files = append(files, e)
+		}
+	}
+	workflows, err := s.list(ctx, ".github/workflows")
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("* .github/workflows: %v", err))
+	}
+	files = append(files, workflows...)
+	ci, err := s.find(ctx, ".gitlab-ci.yml")
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("* .gitlab-ci.yml: %v", err))
+	}
+	if ci != nil {
+		files = append(files, ci)
+	}
+
+	var found bool
+	seen := make(map[string]bool)
+	for _, e := range files {
+		if e.Dir {
+			continue
+		}
+		content, err := s.readEntry(ctx, e)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("* %s: %v", e.Path, err))
+			continue
+		}
+		for _, l := range documentSigningLinks(parseDocument(e.Path, content)) {
+			if seen[l.url] {
+				continue
+			}
+			seen[l.url] = true
+			ev := Evidence{Line: l.line, Snippet: l.snippet, URL: l.url, Provider: l.provider}
+			ev.at(e)
+			s.AddEvidence(ev)
+			found = true
+		}
+	}
+
+	if len(errs) != 0 && !found {
+		return false, fmt.Errorf("%d error(s) checking for CLA signing links:\n\t%s", len(errs), strings.Join(errs, "\n\t"))
+	}
+	return found, nil
+}
+
+// provider picks the CLA service named by the first evidence that names a known one,
+// falling back to ProviderCustom
+func provider(evidence []Evidence) string {
+	var p string
+	for _, ev := range evidence {
+		if ev.Provider == "" || ev.Detector == DCODetector {
+			continue
+		}
+		if ev.Provider != ProviderCustom {
+			return ev.Provider
+		}
+		p = ev.Provider
+	}
+	return p
+}
+
+// signingURLs returns the unique signing links in evidence, in order
+func signingURLs(evidence []Evidence) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, ev := range evidence {
+		if ev.URL == "" || seen[ev.URL] {
+			continue
+		}
+		seen[ev.URL] = true
+		urls = append(urls, ev.URL)
+	}
+	return urls
+}
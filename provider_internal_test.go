@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSigningLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		provider string
+		url      string
+	}{
+		{
+			name:     "cla-assistant badge",
+			content:  "[![CLA assistant](https://cla-assistant.io/readme/badge/o/r)](https://cla-assistant.io/o/r)",
+			provider: ProviderCLAAssistant,
+			url:      "https://cla-assistant.io/o/r",
+		},
+		{
+			name:     "bare cla-assistant link",
+			content:  "Sign at cla-assistant.io/o/r.",
+			provider: ProviderCLAAssistant,
+			url:      "https://cla-assistant.io/o/r",
+		},
+		{
+			name:     "google",
+			content:  "Contributions must be accompanied by a Contributor License Agreement. Visit <https://cla.developers.google.com/> to see your current agreements.",
+			provider: ProviderGoogle,
+			url:      "https://cla.developers.google.com/",
+		},
+		{
+			name:     "microsoft",
+			content:  "For details, visit https://cla.opensource.microsoft.com.",
+			provider: ProviderMicrosoft,
+			url:      "https://cla.opensource.microsoft.com",
+		},
+		{
+			name:     "easycla",
+			content:  "[sign](https://api.easycla.lfx.linuxfoundation.org/v2/repository-provider/github/sign/1/2/3)",
+			provider: ProviderEasyCLA,
+			url:      "https://api.easycla.lfx.linuxfoundation.org/v2/repository-provider/github/sign/1/2/3",
+		},
+		{
+			name:     "apache",
+			content:  "File an [ICLA](https://www.apache.org/licenses/contributor-agreements.html#clas) first.",
+			provider: ProviderApache,
+			url:      "https://www.apache.org/licenses/contributor-agreements.html#clas",
+		},
+		{
+			name:     "custom",
+			content:  "Please sign our CLA at https://example.com/cla before opening a PR.",
+			provider: ProviderCustom,
+			url:      "https://example.com/cla",
+		},
+		{
+			name:     "cla-assistant lite document",
+			content:  "          path-to-document: 'https://github.com/o/r/blob/main/CLA.md'",
+			provider: ProviderCustom,
+			url:      "https://github.com/o/r/blob/main/CLA.md",
+		},
+		{
+			name:    "unrelated link",
+			content: "See https://example.com/docs for more.",
+		},
+		{
+			name:    "repository named like a CLA",
+			content: "You can [submit an issue](https://github.com/progressive-insurance/need-cla/issues).",
+		},
+		{
+			name:    "CLA badge image",
+			content: "![CLA](https://img.shields.io/badge/CLA-required-blue)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := signingLinks([]byte("# r\n" + tt.content))
+			if tt.url == "" {
+				if len(links) != 0 {
+					t.Errorf("expected no links, got %+v", links)
+				}
+				return
+			}
+			if len(links) != 1 || links[0].provider != tt.provider || links[0].url != tt.url || links[0].line != 2 {
+				t.Errorf("got %+v, wanted %s %s on line 2", links, tt.provider, tt.url)
+			}
+		})
+	}
+}
+
+func TestSigningLinksSnippet(t *testing.T) {
+	line := "请签署 https://cla-assistant.io/acme/widgets " + strings.Repeat("贡献者许可协议", 20)
+	links := signingLinks([]byte(line))
+	if len(links) != 1 {
+		t.Fatalf("expected one link, got %+v", links)
+	}
+	if s := links[0].snippet; len(s) > maxSnippet || !utf8.ValidString(s) || !strings.HasPrefix(line, s) {
+		t.Errorf("expected a valid UTF-8 prefix of at most %d bytes, got %q", maxSnippet, s)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	evidence := []Evidence{
+		{Detector: DCODetector, Provider: "dco"},
+		{Detector: SigningLinkDetector, Provider: ProviderCustom},
+		{Detector: SigningLinkDetector, Provider: ProviderGoogle},
+	}
+	if p := provider(evidence); p != ProviderGoogle {
+		t.Errorf("expected a known provider to win over a custom one, got %q", p)
+	}
+	if p := provider(evidence[:2]); p != ProviderCustom {
+		t.Errorf("expected a custom provider, got %q", p)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// defaultHost is assumed for owner/repo shorthand
+const defaultHost = "github.com"
+
+// scpLike matches scp style SSH remotes, like git@github.com:owner/repo.git
+var scpLike = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+\.[\w.-]+):([^/].*)$`)
+
+// gopkgIn matches gopkg.in import paths, which redirect to GitHub
+// https://labix.org/gopkg.in
+var gopkgIn = regexp.MustCompile(`^gopkg\.in/(?:([\w-]+)/)?([\w.-]+?)\.v\d+(?:/.*)?$`)
+
+// Reference identifies a repository on a forge
+type Reference struct {
+	// Host is the forge's host name, like github.com
+	Host string `json:"host"`
+	// Owner is the user, organization, or, on GitLab, the full group path that owns the repository
+	Owner string `json:"owner"`
+	// Repo is the name of the repository
+	Repo string `json:"repo"`
+	// Ambiguous is true if the repository can't be told apart from a directory in it,
+	// like in the Go import path gitlab.com/group/repo/pkg, where groups can be nested.
+	// Resolver looks those up with the go-import meta tag instead.
+	Ambiguous bool `json:"ambiguous,omitempty"`
+}
+
+func (r Reference) String() string {
+	return r.Host + "/" + r.Owner + "/" + r.Repo
+}
+
+// ParseReference normalizes the many ways of naming a repository into a Reference:
+//
+//   - owner/repo shorthand, which is assumed to be on github.com
+//   - web URLs, like https://github.com/google/go-github/tree/master/github
+//   - git remotes, like git@github.com:owner/repo.git or ssh://git@gitlab.com/group/sub/repo.git
+//   - Go import paths, like github.com/spf13/cobra/doc, golang.org/x/oauth2 or gopkg.in/yaml.v3
+//
+// Paths after the repository name are ignored, except on GitLab where groups can be nested
+// and the whole path is used up to a "-", "tree", "blob", "commits" or "merge_requests" segment.
+// A bare GitLab path with more segments than that, like an import path of a package in a module, is marked Ambiguous.
+func ParseReference(s string) (Reference, error) {
+	ref := strings.TrimSpace(s)
+	if ref == "" {
+		return Reference{}, fmt.Errorf("%w: empty", ErrInvalidReference)
+	}
+
+	var host, path string
+	bare := false
+	switch {
+	case strings.Contains(ref, "://"):
+		u, err := url.Parse(ref)
+		if err != nil {
+			return Reference{}, fmt.Errorf("%w %q: %v", ErrInvalidReference, s, err)
+		}
+		host, path = u.Hostname(), u.Path
+	case scpLike.MatchString(ref):
+		m := scpLike.FindStringSubmatch(ref)
+		host, path = m[1], m[2]
+	default:
+		segments := strings.SplitN(ref, "/", 2)
+		if strings.Contains(segments[0], ".") && len(segments) == 2 {
+			host, path, bare = segments[0], segments[1], true
+		} else {
+			host, path = defaultHost, ref
+		}
+	}
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+
+	if r, ok := vanityReference(host, path); ok {
+		return r, nil
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	gitlab := ForgeKind(host) == ForgeGitLab
+	if gitlab {
+		// web URLs put the page after the project, like /group/repo/-/tree/main or, on older instances, /group/repo/tree/main
+		for i := 2; i < len(segments); i++ {
+			if gitlabPages[segments[i]] {
+				segments = segments[:i]
+				break
+			}
+		}
+	} else if len(segments) > 2 {
+		segments = segments[:2]
+	}
+	if len(segments) < 2 {
+		return Reference{}, fmt.Errorf("%w %q: expected an owner and a repository", ErrInvalidReference, s)
+	}
+	for _, seg := range segments {
+		if seg == "" || seg == "." || seg == ".." {
+			return Reference{}, fmt.Errorf("%w %q: empty path segment", ErrInvalidReference, s)
+		}
+	}
+
+	return Reference{
+		Host:      host,
+		Owner:     strings.Join(segments[:len(segments)-1], "/"),
+		Repo:      strings.TrimSuffix(segments[len(segments)-1], ".git"),
+		Ambiguous: gitlab && bare && len(segments) > 2,
+	}, nil
+}
+
+// gitlabPages are the path segments GitLab web URLs use after a project, which can't be group or project names
+var gitlabPages = map[string]bool{"-": true, "tree": true, "blob": true, "commits": true, "merge_requests": true}
+
+// vanityReference resolves Go import paths on well known vanity hosts to the repositories they redirect to
+func vanityReference(host, path string) (Reference, bool) {
+	switch host {
+	case "golang.org":
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		if len(segments) >= 2 && segments[0] == "x" {
+			return Reference{Host: defaultHost, Owner: "golang", Repo: segments[1]}, true
+		}
+	case "gopkg.in":
+		m := gopkgIn.FindStringSubmatch(host + "/" + strings.Trim(path, "/"))
+		if m == nil {
+			return Reference{}, false
+		}
+		owner := m[1]
+		if owner == "" {
+			// gopkg.in/pkg.v3 is github.com/go-pkg/pkg
+			owner = "go-" + m[2]
+		}
+		return Reference{Host: defaultHost, Owner: owner, Repo: m[2]}, true
+	}
+	return Reference{}, false
+}
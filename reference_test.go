@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla_test
+
+import (
+	"errors"
+	"testing"
+
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		in   string
+		want needcla.Reference
+	}{
+		{"google/go-github", needcla.Reference{Host: "github.com", Owner: "google", Repo: "go-github"}},
+		{"https://github.com/google/go-github", needcla.Reference{Host: "github.com", Owner: "google", Repo: "go-github"}},
+		{"https://www.github.com/google/go-github/", needcla.Reference{Host: "github.com", Owner: "google", Repo: "go-github"}},
+		{"https://github.com/google/go-github/tree/master/github", needcla.Reference{Host: "github.com", Owner: "google", Repo: "go-github"}},
+		{"https://github.com/google/go-github.git", needcla.Reference{Host: "github.com", Owner: "google", Repo: "go-github"}},
+		{"git@github.com:owner/repo.git", needcla.Reference{Host: "github.com", Owner: "owner", Repo: "repo"}},
+		{"ssh://git@github.com/owner/repo.git", needcla.Reference{Host: "github.com", Owner: "owner", Repo: "repo"}},
+		{"git+ssh://git@github.com:22/owner/repo", needcla.Reference{Host: "github.com", Owner: "owner", Repo: "repo"}},
+		{"github.com/spf13/cobra/doc", needcla.Reference{Host: "github.com", Owner: "spf13", Repo: "cobra"}},
+		{"codeberg.org/forgejo/forgejo", needcla.Reference{Host: "codeberg.org", Owner: "forgejo", Repo: "forgejo"}},
+		{"https://gitlab.com/gitlab-org/security/gitlab", needcla.Reference{Host: "gitlab.com", Owner: "gitlab-org/security", Repo: "gitlab"}},
+		{"https://gitlab.com/gitlab-org/gitlab/-/tree/master/doc", needcla.Reference{Host: "gitlab.com", Owner: "gitlab-org", Repo: "gitlab"}},
+		{"https://gitlab.com/group/sub/repo/tree/main", needcla.Reference{Host: "gitlab.com", Owner: "group/sub", Repo: "repo"}},
+		{"https://gitlab.com/group/repo/blob/main/go.mod", needcla.Reference{Host: "gitlab.com", Owner: "group", Repo: "repo"}},
+		{"https://gitlab.com/group/repo/merge_requests/12", needcla.Reference{Host: "gitlab.com", Owner: "group", Repo: "repo"}},
+		{"gitlab.com/group/repo", needcla.Reference{Host: "gitlab.com", Owner: "group", Repo: "repo"}},
+		{"gitlab.com/group/repo/subpkg", needcla.Reference{Host: "gitlab.com", Owner: "group/repo", Repo: "subpkg", Ambiguous: true}},
+		{"git@gitlab.com:group/sub/project.git", needcla.Reference{Host: "gitlab.com", Owner: "group/sub", Repo: "project"}},
+		{"https://bitbucket.org/workspace/repo/src/main/", needcla.Reference{Host: "bitbucket.org", Owner: "workspace", Repo: "repo"}},
+		{"golang.org/x/oauth2", needcla.Reference{Host: "github.com", Owner: "golang", Repo: "oauth2"}},
+		{"golang.org/x/net/http2", needcla.Reference{Host: "github.com", Owner: "golang", Repo: "net"}},
+		{"gopkg.in/yaml.v3", needcla.Reference{Host: "github.com", Owner: "go-yaml", Repo: "yaml"}},
+		{"gopkg.in/src-d/go-git.v4/plumbing", needcla.Reference{Host: "github.com", Owner: "src-d", Repo: "go-git"}},
+		{"  progressive-insurance/need-cla\n", needcla.Reference{Host: "github.com", Owner: "progressive-insurance", Repo: "need-cla"}},
+	}
+	for _, tt := range tests {
+		got, err := needcla.ParseReference(tt.in)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %+v, wanted %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseReferenceInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"need-cla",
+		"https://github.com/google",
+		"github.com/google",
+		"google//go-github",
+		"https://github.com",
+	} {
+		if _, err := needcla.ParseReference(in); !errors.Is(err, needcla.ErrInvalidReference) {
+			t.Errorf("%q: expected ErrInvalidReference, got %v", in, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import "errors"
+
+// ReportVersion is the version of the Report JSON schema.
+// It's incremented when a field is removed or changes meaning, not when fields are added.
+const ReportVersion = 1
+
+// Report is a stable, versioned summary of checking a repository, meant to be encoded as JSON for scripts
+type Report struct {
+	// Version is the ReportVersion the report was written with
+	Version int `json:"version"`
+	// Repository is the repository that was checked
+	Repository Reference `json:"repository"`
+	// Branch is the branch that was checked
+	Branch string `json:"branch,omitempty"`
+	// Commit is the SHA of the commit that was checked, if the forge reports it
+	Commit string `json:"commit,omitempty"`
+	// Required is Details.Required
+	Required bool `json:"required"`
+	// Confidence is Details.Confidence
+	Confidence float64 `json:"confidence"`
+	// Verdict is Details.Verdict: "required", "not required" or "uncertain"
+	Verdict Verdict `json:"verdict"`
+	// Provider is Details.Provider, the CLA service the evidence points to, if one was recognized
+	Provider string `json:"provider,omitempty"`
+	// SigningURLs is Details.SigningURLs, where contributors sign the CLA
+	SigningURLs []string `json:"signing_urls,omitempty"`
+	// DCO is true if the repository asks for commits to be signed off under a Developer Certificate of Origin,
+	// which is reported apart from the CLA verdict
+	DCO bool `json:"dco"`
+	// Docs is Details.Docs, the paths of the community documents that were checked
+	Docs map[string]string `json:"docs,omitempty"`
+	// Checks are the results of every registered detector, in registration order
+	Checks []CheckReport `json:"checks"`
+	// Error is set if the repository couldn't be checked at all
+	Error string `json:"error,omitempty"`
+}
+
+// CheckReport is the result of one detector
+type CheckReport struct {
+	// Name is the detector's name, like "contributing"
+	Name string `json:"name"`
+	// Description is a short sentence saying what the detector looks for
+	Description string `json:"description"`
+	// Found is true if the detector found a CLA
+	Found bool `json:"found"`
+	// Unknown is true if the detector couldn't tell either way, like when the token lacks a permission it needs
+	Unknown bool `json:"unknown,omitempty"`
+	// Error is set if the detector failed, in which case Found may be a false negative
+	Error string `json:"error,omitempty"`
+	// Evidence is what made the detector find a CLA
+	Evidence []Evidence `json:"evidence,omitempty"`
+}
+
+// NewReport summarizes the results of checking ref, as returned by DetailWithContext and the other Detail functions
+func NewReport(ref Reference, d Details, err error) Report {
+	r := Report{
+		Version:     ReportVersion,
+		Repository:  ref,
+		Branch:      d.Branch,
+		Commit:      d.Commit,
+		Required:    d.Required(),
+		Confidence:  d.Confidence(),
+		Verdict:     d.Verdict(),
+		Provider:    d.Provider,
+		SigningURLs: d.SigningURLs,
+		DCO:         d.Result(DCODetector),
+		Docs:        d.Docs,
+		Checks:      []CheckReport{},
+	}
+	var e *Errors
+	if err != nil && !errors.As(err, &e) {
+		r.Error = err.Error()
+	}
+	for _, det := range Detectors() {
+		check := CheckReport{
+			Name:        det.Name(),
+			Description: det.Description(),
+			Found:       d.Result(det.Name()),
+			Unknown:     d.IsUnknown(det.Name()),
+		}
+		for _, ev := range d.Evidence {
+			if ev.Detector == det.Name() {
+				check.Evidence = append(check.Evidence, ev)
+			}
+		}
+		if e != nil {
+			if err := e.Err(det.Name()); err != nil {
+				check.Error = err.Error()
+			}
+		}
+		r.Checks = append(r.Checks, check)
+	}
+	return r
+}
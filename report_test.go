@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+func TestNewReport(t *testing.T) {
+	ref := needcla.Reference{Host: "github.com", Owner: "o", Repo: "r"}
+	d := needcla.Details{InContributing: true, Branch: "main", Commit: "abc123"}
+	e := &needcla.Errors{TagErr: errors.New("rate limited")}
+
+	r := needcla.NewReport(ref, d, e)
+	if r.Version != needcla.ReportVersion || r.Repository != ref || r.Branch != "main" || r.Commit != "abc123" {
+		t.Errorf("unexpected report header %+v", r)
+	}
+	if !r.Required || r.Error != "" {
+		t.Errorf("expected a required verdict without a fatal error, got %+v", r)
+	}
+	checks := make(map[string]needcla.CheckReport)
+	for _, c := range r.Checks {
+		checks[c.Name] = c
+	}
+	if !checks[needcla.InContributingDetector].Found {
+		t.Errorf("expected the contributing check to be found, got %+v", checks[needcla.InContributingDetector])
+	}
+	if checks[needcla.TagDetector].Error != "rate limited" {
+		t.Errorf("expected the label check's error, got %+v", checks[needcla.TagDetector])
+	}
+
+	r = needcla.NewReport(ref, needcla.Details{}, needcla.ErrNotFound)
+	if r.Required || r.Error != needcla.ErrNotFound.Error() {
+		t.Errorf("expected a fatal error, got %+v", r)
+	}
+}
+
+func TestDetailsJSON(t *testing.T) {
+	b, err := json.Marshal(needcla.Details{Known: true, Action: true, Results: map[string]bool{needcla.DCODetector: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"known-owner":true,"pr-label":false,"clabot-file":false,"contributing":false,"readme":false,"cla-assistant-action":true,"results":{"dco":true}}`
+	if string(b) != expected {
+		t.Errorf("unexpected json,\nexpected: %s\ngot:      %s", expected, b)
+	}
+}
@@ -0,0 +1,260 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// registries are the default package registries dependencies are resolved with
+var registries = map[string]string{
+	EcosystemGo:    "https://",
+	EcosystemNPM:   "https://registry.npmjs.org",
+	EcosystemPyPI:  "https://pypi.org",
+	EcosystemCargo: "https://crates.io",
+	EcosystemMaven: "https://repo1.maven.org/maven2",
+}
+
+// metaTag matches the HTML meta tags that go-get=1 responses use to name a module's repository
+var metaTag = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+var metaAttr = regexp.MustCompile(`(?is)\b(name|content)\s*=\s*["']([^"']*)["']`)
+
+// pypiURLKeys are the project_urls keys most likely to link to the source repository, in order of preference
+var pypiURLKeys = []string{"Source", "Source Code", "Repository", "Code", "GitHub", "Homepage"}
+
+// Resolver finds the source repositories of dependencies by asking their package registries.
+// The zero value is ready to use.
+type Resolver struct {
+	// Client makes registry requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Registries overrides the registry base URL of an ecosystem, like a mirror or private registry.
+	// Go modules are looked up with go-get=1 requests to the Go registry followed by the module path.
+	Registries map[string]string
+}
+
+// Resolve returns the source repository of dep
+func (r *Resolver) Resolve(ctx context.Context, dep Dependency) (Reference, error) {
+	if r == nil {
+		r = &Resolver{}
+	}
+	var candidates []string
+	var err error
+	switch dep.Ecosystem {
+	case EcosystemGo:
+		candidates, err = r.goRepository(ctx, dep.Name)
+	case EcosystemNPM:
+		candidates, err = r.npmRepository(ctx, dep.Name)
+	case EcosystemPyPI:
+		candidates, err = r.pypiRepository(ctx, dep.Name)
+	case EcosystemCargo:
+		candidates, err = r.cargoRepository(ctx, dep.Name)
+	case EcosystemMaven:
+		candidates, err = r.mavenRepository(ctx, dep.Name, dep.Version)
+	default:
+		return Reference{}, fmt.Errorf("%s: unknown ecosystem %q", dep.Name, dep.Ecosystem)
+	}
+	if err != nil {
+		return Reference{}, fmt.Errorf("%s: %w", dep.Name, err)
+	}
+	for _, c := range candidates {
+		ref, err := ParseReference(normalizeRepositoryURL(c))
+		if err == nil && ForgeKind(ref.Host) != "" {
+			return ref, nil
+		}
+	}
+	return Reference{}, fmt.Errorf("%s: %w", dep.Name, ErrUnresolvedDependency)
+}
+
+func (r *Resolver) registry(ecosystem string) (*restClient, error) {
+	base, ok := r.Registries[ecosystem]
+	if !ok {
+		base = registries[ecosystem]
+	}
+	return newRESTClient(r.Client, base, func(req *http.Request) {
+		// crates.io rejects requests without a user agent
+		req.Header.Set("User-Agent", "need-cla (https://github.com/progressive-insurance/need-cla)")
+	})
+}
+
+// fixedRepositoryHosts are the hosts whose import paths always name the repository in the first two segments
+var fixedRepositoryHosts = map[string]bool{"github.com": true, "bitbucket.org": true}
+
+// goRepository resolves a module path, following the go-import meta tag of vanity import paths
+// and of hosts like GitLab where the repository can't be told from the path
+// https://go.dev/ref/mod#vcs-find
+func (r *Resolver) goRepository(ctx context.Context, path string) ([]string, error) {
+	if ref, err := ParseReference(path); err == nil && fixedRepositoryHosts[ref.Host] && !ref.Ambiguous {
+		return []string{path}, nil
+	}
+	base, ok := r.Registries[EcosystemGo]
+	if !ok {
+		base = registries[EcosystemGo]
+	}
+	u, err := url.Parse(base + path)
+	if err != nil {
+		return nil, err
+	}
+	api := &restClient{client: r.Client, baseURL: u}
+	if api.client == nil {
+		api.client = http.DefaultClient
+	}
+	var page []byte
+	if _, err := api.getURL(ctx, u, url.Values{"go-get": {"1"}}, &page); err != nil {
+		return nil, err
+	}
+	for _, tag := range metaTag.FindAllString(string(page), -1) {
+		attrs := make(map[string]string)
+		for _, m := range metaAttr.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = m[2]
+		}
+		fields := strings.Fields(attrs["content"])
+		if attrs["name"] != "go-import" || len(fields) != 3 {
+			continue
+		}
+		if path == fields[0] || strings.HasPrefix(path, fields[0]+"/") {
+			return []string{fields[2]}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) npmRepository(ctx context.Context, name string) ([]string, error) {
+	api, err := r.registry(EcosystemNPM)
+	if err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Repository json.RawMessage `json:"repository"`
+		Homepage   string          `json:"homepage"`
+	}
+	// scoped packages keep their @ but escape the slash
+	if _, err := api.get(ctx, strings.Replace(url.PathEscape(name), "%40", "@", 1), nil, &pkg); err != nil {
+		return nil, err
+	}
+	var repo struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(pkg.Repository, &repo.URL); err != nil {
+		json.Unmarshal(pkg.Repository, &repo)
+	}
+	return []string{repo.URL, pkg.Homepage}, nil
+}
+
+func (r *Resolver) pypiRepository(ctx context.Context, name string) ([]string, error) {
+	if strings.Contains(name, "://") {
+		return []string{name}, nil
+	}
+	api, err := r.registry(EcosystemPyPI)
+	if err != nil {
+		return nil, err
+	}
+	var project struct {
+		Info struct {
+			ProjectURLs map[string]string `json:"project_urls"`
+			HomePage    string            `json:"home_page"`
+		} `json:"info"`
+	}
+	if _, err := api.get(ctx, "pypi/"+url.PathEscape(name)+"/json", nil, &project); err != nil {
+		return nil, err
+	}
+	var candidates []string
+	for _, key := range pypiURLKeys {
+		if u, ok := project.Info.ProjectURLs[key]; ok {
+			candidates = append(candidates, u)
+		}
+	}
+	for _, key := range sortedKeys(project.Info.ProjectURLs) {
+		candidates = append(candidates, project.Info.ProjectURLs[key])
+	}
+	return append(candidates, project.Info.HomePage), nil
+}
+
+func (r *Resolver) cargoRepository(ctx context.Context, name string) ([]string, error) {
+	api, err := r.registry(EcosystemCargo)
+	if err != nil {
+		return nil, err
+	}
+	var crate struct {
+		Crate struct {
+			Repository string `json:"repository"`
+			Homepage   string `json:"homepage"`
+		} `json:"crate"`
+	}
+	if _, err := api.get(ctx, "api/v1/crates/"+url.PathEscape(name), nil, &crate); err != nil {
+		return nil, err
+	}
+	return []string{crate.Crate.Repository, crate.Crate.Homepage}, nil
+}
+
+// mavenRepository reads the scm section of an artifact's POM, using the latest release if version isn't pinned
+func (r *Resolver) mavenRepository(ctx context.Context, name, version string) ([]string, error) {
+	api, err := r.registry(EcosystemMaven)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(name, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected groupId:artifactId")
+	}
+	dir := strings.ReplaceAll(parts[0], ".", "/") + "/" + parts[1] + "/"
+	if version == "" || strings.Contains(version, "${") {
+		var metadata []byte
+		if _, err := api.get(ctx, dir+"maven-metadata.xml", nil, &metadata); err != nil {
+			return nil, err
+		}
+		var m struct {
+			Release string `xml:"versioning>release"`
+			Latest  string `xml:"versioning>latest"`
+		}
+		if err := xml.Unmarshal(metadata, &m); err != nil {
+			return nil, err
+		}
+		version = m.Release
+		if version == "" {
+			version = m.Latest
+		}
+	}
+	var content []byte
+	if _, err := api.get(ctx, dir+version+"/"+parts[1]+"-"+version+".pom", nil, &content); err != nil {
+		return nil, err
+	}
+	var pom struct {
+		SCM struct {
+			URL        string `xml:"url"`
+			Connection string `xml:"connection"`
+		} `xml:"scm"`
+		URL string `xml:"url"`
+	}
+	if err := xml.Unmarshal(content, &pom); err != nil {
+		return nil, err
+	}
+	return []string{pom.SCM.URL, pom.SCM.Connection, pom.URL}, nil
+}
+
+// normalizeRepositoryURL strips the prefixes package registries put in front of repository URLs
+func normalizeRepositoryURL(u string) string {
+	u = strings.TrimSpace(u)
+	u = strings.TrimPrefix(u, "scm:git:")
+	u = strings.TrimPrefix(u, "git+")
+	if strings.HasPrefix(u, "git://") {
+		u = "https://" + strings.TrimPrefix(u, "git://")
+	}
+	for prefix, host := range map[string]string{"github:": "github.com/", "gitlab:": "gitlab.com/", "bitbucket:": "bitbucket.org/"} {
+		if strings.HasPrefix(u, prefix) {
+			return host + strings.TrimPrefix(u, prefix)
+		}
+	}
+	return u
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+func newTestResolver(t *testing.T) *needcla.Resolver {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/go/go.uber.org/zap":
+			fmt.Fprint(w, `<html><head><meta name="go-import" content="go.uber.org/zap git https://github.com/uber-go/zap"></head></html>`)
+		case "/go/gitlab.com/group/sub/repo/v2":
+			fmt.Fprint(w, `<html><head><meta name="go-import" content="gitlab.com/group/sub/repo git https://gitlab.com/group/sub/repo.git"></head></html>`)
+		case "/npm/@babel%2Fcore", "/npm/@babel/core":
+			fmt.Fprint(w, `{"repository": {"type": "git", "url": "git+https://github.com/babel/babel.git"}}`)
+		case "/npm/express":
+			fmt.Fprint(w, `{"repository": {"type": "git", "url": "git://github.com/expressjs/express.git"}}`)
+		case "/npm/debug":
+			fmt.Fprint(w, `{"repository": {"type": "git", "url": "git+git://github.com/debug-js/debug.git"}}`)
+		case "/npm/left-pad":
+			fmt.Fprint(w, `{"repository": "github:stevemao/left-pad"}`)
+		case "/pypi/pypi/requests/json":
+			fmt.Fprint(w, `{"info": {"project_urls": {"Documentation": "https://requests.readthedocs.io", "Source": "https://github.com/psf/requests"}}}`)
+		case "/cargo/api/v1/crates/serde":
+			if r.Header.Get("User-Agent") == "" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"crate": {"repository": "https://github.com/serde-rs/serde"}}`)
+		case "/maven/org/slf4j/slf4j-api/maven-metadata.xml":
+			fmt.Fprint(w, `<metadata><versioning><release>1.7.36</release></versioning></metadata>`)
+		case "/maven/com/google/guava/guava/maven-metadata.xml":
+			fmt.Fprint(w, `<metadata><versioning><release>31.1-jre</release></versioning></metadata>`)
+		case "/maven/com/google/guava/guava/31.1-jre/guava-31.1-jre.pom":
+			fmt.Fprint(w, `<project><scm><connection>scm:git:git://github.com/google/guava.git</connection></scm></project>`)
+		case "/maven/org/slf4j/slf4j-api/1.7.36/slf4j-api-1.7.36.pom":
+			fmt.Fprint(w, `<project><url>http://www.slf4j.org</url><scm><url>https://github.com/qos-ch/slf4j</url></scm></project>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return &needcla.Resolver{
+		Client: srv.Client(),
+		Registries: map[string]string{
+			needcla.EcosystemGo:    srv.URL + "/go/",
+			needcla.EcosystemNPM:   srv.URL + "/npm",
+			needcla.EcosystemPyPI:  srv.URL + "/pypi",
+			needcla.EcosystemCargo: srv.URL + "/cargo",
+			needcla.EcosystemMaven: srv.URL + "/maven",
+		},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	r := newTestResolver(t)
+	tests := []struct {
+		dep  needcla.Dependency
+		want string
+	}{
+		{needcla.Dependency{Name: "github.com/google/go-github/v43", Ecosystem: needcla.EcosystemGo}, "github.com/google/go-github"},
+		{needcla.Dependency{Name: "golang.org/x/oauth2", Ecosystem: needcla.EcosystemGo}, "github.com/golang/oauth2"},
+		{needcla.Dependency{Name: "go.uber.org/zap", Ecosystem: needcla.EcosystemGo}, "github.com/uber-go/zap"},
+		{needcla.Dependency{Name: "gitlab.com/group/sub/repo/v2", Ecosystem: needcla.EcosystemGo}, "gitlab.com/group/sub/repo"},
+		{needcla.Dependency{Name: "@babel/core", Ecosystem: needcla.EcosystemNPM}, "github.com/babel/babel"},
+		{needcla.Dependency{Name: "express", Ecosystem: needcla.EcosystemNPM}, "github.com/expressjs/express"},
+		{needcla.Dependency{Name: "debug", Ecosystem: needcla.EcosystemNPM}, "github.com/debug-js/debug"},
+		{needcla.Dependency{Name: "left-pad", Ecosystem: needcla.EcosystemNPM}, "github.com/stevemao/left-pad"},
+		{needcla.Dependency{Name: "requests", Ecosystem: needcla.EcosystemPyPI}, "github.com/psf/requests"},
+		{needcla.Dependency{Name: "https://gitlab.com/group/sub/pkg.git", Ecosystem: needcla.EcosystemPyPI}, "gitlab.com/group/sub/pkg"},
+		{needcla.Dependency{Name: "serde", Ecosystem: needcla.EcosystemCargo}, "github.com/serde-rs/serde"},
+		{needcla.Dependency{Name: "com.google.guava:guava", Ecosystem: needcla.EcosystemMaven}, "github.com/google/guava"},
+		{needcla.Dependency{Name: "org.slf4j:slf4j-api", Version: "${slf4j.version}", Ecosystem: needcla.EcosystemMaven}, "github.com/qos-ch/slf4j"},
+	}
+	for _, tt := range tests {
+		ref, err := r.Resolve(context.Background(), tt.dep)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.dep.Name, err)
+			continue
+		}
+		if ref.String() != tt.want {
+			t.Errorf("%s: got %s, wanted %s", tt.dep.Name, ref, tt.want)
+		}
+	}
+
+	_, err := r.Resolve(context.Background(), needcla.Dependency{Name: "missing", Ecosystem: needcla.EcosystemNPM})
+	if !errors.Is(err, needcla.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing package, got %v", err)
+	}
+}
+
+func TestDetailDependencies(t *testing.T) {
+	deps := []needcla.Dependency{
+		{Name: "github.com/owner/repo/v2", Ecosystem: needcla.EcosystemGo},
+		{Name: "github.com/owner/repo/sub", Ecosystem: needcla.EcosystemGo},
+		{Name: "github.com/owner/other", Ecosystem: needcla.EcosystemGo},
+		{Name: "unknown", Ecosystem: "other"},
+	}
+	var checked []string
+	detail := func(ctx context.Context, ref needcla.Reference) (needcla.Details, error) {
+		checked = append(checked, ref.String())
+		return needcla.Details{InContributing: ref.Repo == "repo"}, nil
+	}
+
+	results := needcla.DetailDependencies(context.Background(), nil, deps, detail)
+	if len(checked) != 2 {
+		t.Errorf("expected each repository to be checked once, checked %v", checked)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", results)
+	}
+	if len(results[0].Dependencies) != 2 || !results[0].Details.Required() {
+		t.Errorf("expected both modules of owner/repo in a result that requires a CLA, got %+v", results[0])
+	}
+	if results[1].Reference.Repo != "other" || results[1].Details.Required() {
+		t.Errorf("unexpected result for owner/other: %+v", results[1])
+	}
+	if results[2].Err == nil {
+		t.Errorf("expected an error for an unknown ecosystem, got %+v", results[2])
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/go-github/v43/github"
+)
+
+// defaultWorkers is how many repositories ScanOrg checks at once if ScanOptions.Workers isn't set
+const defaultWorkers = 4
+
+// ScanOptions configures ScanOrg
+type ScanOptions struct {
+	Options
+	// Workers is how many repositories are checked at once
+	Workers int
+	// SkipArchived leaves out archived repositories
+	SkipArchived bool
+	// SkipForks leaves out forks
+	SkipForks bool
+}
+
+// ScanResult is the outcome of checking one repository in an organization
+type ScanResult struct {
+	// Repo is the name of the repository, without the owner
+	Repo string
+	// Details are the results of the checks
+	Details Details
+	// Err is non-nil if any check failed
+	Err error
+}
+
+// ScanOrg checks every repository in the GitHub organization org, calling fn with each result as it completes.
+// Repositories are checked concurrently, sharing opts.RateBudget, or a new one if it's nil,
+// so the rate limit is planned across the whole scan. fn is never called concurrently.
+// The returned error is from listing the organization's repositories; per repository errors are in ScanResult.Err.
+func ScanOrg(ctx context.Context, client *github.Client, org string, opts ScanOptions, fn func(ScanResult)) error {
+	if opts.RateBudget == nil {
+		opts.RateBudget = new(RateBudget)
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	repos := make(chan string)
+	results := make(chan ScanResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range repos {
+				d, err := DetailWithOptions(ctx, client, org, repo, opts.Options)
+				results <- ScanResult{Repo: repo, Details: d, Err: err}
+			}
+		}()
+	}
+
+	var listErr error
+	go func() {
+		defer func() {
+			close(repos)
+			wg.Wait()
+			close(results)
+		}()
+		listErr = listOrgRepos(ctx, client, org, opts, repos)
+	}()
+
+	for r := range results {
+		fn(r)
+	}
+	return listErr
+}
+
+// listOrgRepos sends the name of every repository in org that opts doesn't skip to repos
+func listOrgRepos(ctx context.Context, client *github.Client, org string, opts ScanOptions, repos chan<- string) error {
+	listOpts := &github.RepositoryListByOrgOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		if _, err := opts.RateBudget.reserve(ctx, client, func(Cost) Cost { return Cost{Core: 1} }); err != nil {
+			return err
+		}
+		page, resp, err := client.Repositories.ListByOrg(ctx, org, listOpts)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%s: %w", org, ErrNotFound)
+		}
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return ErrInvalidToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list repositories of %s: %w", org, err)
+		}
+		for _, r := range page {
+			if (opts.SkipArchived && r.GetArchived()) || (opts.SkipForks && r.GetFork()) {
+				continue
+			}
+			select {
+			case repos <- r.GetName():
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil
+		}
+		listOpts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v43/github"
+)
+
+// newScanMux serves an organization with an active, an archived and a forked repository,
+// where only active has a CONTRIBUTING.md mentioning a CLA
+func newScanMux(core int, rateLimitCalls *int32) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(rateLimitCalls, 1)
+		reset := github.Timestamp{Time: time.Now().Add(time.Hour)}
+		writeJSON(w, map[string]interface{}{"resources": map[string]interface{}{
+			"core": github.Rate{Limit: 5000, Remaining: core, Reset: reset},
+		}})
+	})
+	mux.HandleFunc("/orgs/o/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			writeJSON(w, []*github.Repository{{Name: github.String("forked"), Fork: github.Bool(true)}})
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+		writeJSON(w, []*github.Repository{
+			{Name: github.String("active")},
+			{Name: github.String("archived"), Archived: github.Bool(true)},
+		})
+	})
+	mux.HandleFunc("/repos/o/", func(w http.ResponseWriter, r *http.Request) {
+		segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/repos/o/"), "/")
+		repo := segments[0]
+		switch {
+		case len(segments) == 1:
+			writeJSON(w, github.Repository{DefaultBranch: github.String("main")})
+		case segments[1] == "commits" && len(segments) == 3:
+			w.Write([]byte("0123456789abcdef0123456789abcdef01234567"))
+		case segments[1] == "commits":
+			writeJSON(w, []*github.RepositoryCommit{})
+		case segments[1] == "git" && segments[2] == "trees":
+			var entries []*github.TreeEntry
+			if repo == "active" {
+				entries = append(entries, &github.TreeEntry{Path: github.String("CONTRIBUTING.md"), Type: github.String("blob"), SHA: github.String("contributing")})
+			}
+			writeJSON(w, github.Tree{Entries: entries})
+		case segments[1] == "git" && segments[2] == "blobs":
+			content := base64.StdEncoding.EncodeToString([]byte("Sign the Contributor License Agreement first."))
+			writeJSON(w, github.Blob{Content: github.String(content), Encoding: github.String("base64")})
+		case segments[1] == "pulls":
+			writeJSON(w, []*github.PullRequest{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return mux
+}
+
+func TestScanOrg(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, newScanMux(5000, &calls))
+
+	var repos []string
+	required := make(map[string]bool)
+	err := ScanOrg(context.Background(), client, "o", ScanOptions{SkipArchived: true, Workers: 2}, func(r ScanResult) {
+		if r.Err != nil {
+			t.Errorf("%s: unexpected error: %v", r.Repo, r.Err)
+		}
+		repos = append(repos, r.Repo)
+		required[r.Repo] = r.Details.Required()
+		if r.Details.Branch != "main" || r.Details.Commit == "" {
+			t.Errorf("%s: expected the checked branch and commit, got %q and %q", r.Repo, r.Details.Branch, r.Details.Commit)
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(repos)
+	if strings.Join(repos, ",") != "active,forked" {
+		t.Errorf("expected active and forked to be checked, got %v", repos)
+	}
+	if !required["active"] || required["forked"] {
+		t.Errorf("unexpected results %v", required)
+	}
+	if calls != 1 {
+		t.Errorf("expected the rate limit to be fetched once for the whole scan, got %d", calls)
+	}
+}
+
+func TestScanOrgSharesBudget(t *testing.T) {
+	var calls int32
+	// enough for listing two pages and checking one repository, but not two
+	client := newTestClient(t, newScanMux(36, &calls))
+
+	var failed int
+	err := ScanOrg(context.Background(), client, "o", ScanOptions{SkipForks: true, Workers: 2}, func(r ScanResult) {
+		if errors.Is(r.Err, ErrRateLimitBudget) {
+			failed++
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failed != 1 {
+		t.Errorf("expected one of the two repositories to be over budget, %d were", failed)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import "fmt"
+
+// Verdict is the conclusion drawn from a confidence score
+type Verdict int
+
+const (
+	// VerdictUncertain means the signals were too weak or contradictory to decide
+	VerdictUncertain Verdict = iota
+	// VerdictRequired means contributors need to sign a CLA
+	VerdictRequired
+	// VerdictNotRequired means contributors don't need to sign a CLA
+	VerdictNotRequired
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case VerdictRequired:
+		return "required"
+	case VerdictNotRequired:
+		return "not required"
+	case VerdictUncertain:
+		return "uncertain"
+	}
+	return fmt.Sprintf("Verdict(%d)", int(v))
+}
+
+func (v Verdict) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// Scoring weighs detector results into a confidence that a repository requires a CLA.
+//
+// Each detector that finds something contributes its weight.
+// Positive weights combine so that independent signals reinforce each other without exceeding 1,
+// and negative weights, from detectors that find evidence against a CLA, scale the result down:
+//
+//	confidence = (1 - (1-w₁)(1-w₂)...) × (1-|n₁|)(1-|n₂|)...
+type Scoring struct {
+	// Weights are keyed by detector name and range from -1 to 1
+	Weights map[string]float64
+	// DefaultWeight is used for detectors without a weight, like custom detectors
+	DefaultWeight float64
+	// HeadingBoost is added to the positive weight of a detector whose evidence is under a heading
+	// where a CLA policy would be stated, like "Contributing" or "Legal"
+	HeadingBoost float64
+	// RequiredAt is the confidence at or above which the verdict is VerdictRequired
+	RequiredAt float64
+	// NotRequiredAt is the confidence at or below which the verdict is VerdictNotRequired
+	NotRequiredAt float64
+}
+
+// DefaultScoring is used by Details.Required, Details.Confidence and Details.Verdict
+var DefaultScoring = Scoring{
+	Weights: map[string]float64{
+		KnownDetector:          0.9,
+		TagDetector:            0.8,
+		StatusDetector:         0.9,
+		RequiredCheckDetector:  0.99,
+		CommentDetector:        0.9,
+		BotFileDetector:        0.9,
+		InContributingDetector: 0.7,
+		InREADMEDetector:       0.4,
+		TemplateDetector:       0.8,
+		ActionDetector:         0.95,
+		NoCLADetector:          -0.8,
+		EasyCLADetector:        0.95,
+		SigningLinkDetector:    0.85,
+	},
+	DefaultWeight: 0.5,
+	HeadingBoost:  0.15,
+	RequiredAt:    0.7,
+	NotRequiredAt: 0.3,
+}
+
+// Weight returns the weight of the detector with the given name
+func (sc Scoring) Weight(name string) float64 {
+	if w, ok := sc.Weights[name]; ok {
+		return w
+	}
+	return sc.DefaultWeight
+}
+
+// Confidence returns how sure sc is that d's repository requires a CLA, from 0 to 1
+func (sc Scoring) Confidence(d Details) float64 {
+	boosted := make(map[string]bool)
+	for _, ev := range d.Evidence {
+		if isRelevantHeading(ev.Heading) {
+			boosted[ev.Detector] = true
+		}
+	}
+	missed, kept := 1.0, 1.0
+	d.each(func(name string, found bool) {
+		if !found {
+			return
+		}
+		w := sc.Weight(name)
+		if w > 0 && boosted[name] {
+			w += sc.HeadingBoost
+		}
+		if w > 1 {
+			w = 1
+		}
+		if w < -1 {
+			w = -1
+		}
+		if w > 0 {
+			missed *= 1 - w
+		} else {
+			kept *= 1 + w
+		}
+	})
+	return (1 - missed) * kept
+}
+
+// Verdict compares d's confidence with sc's thresholds
+func (sc Scoring) Verdict(d Details) Verdict {
+	c := sc.Confidence(d)
+	switch {
+	case c >= sc.RequiredAt:
+		return VerdictRequired
+	case c <= sc.NotRequiredAt:
+		return VerdictNotRequired
+	}
+	return VerdictUncertain
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla_test
+
+import (
+	"math"
+	"testing"
+
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+func TestScoring(t *testing.T) {
+	tests := []struct {
+		name       string
+		d          needcla.Details
+		confidence float64
+		verdict    needcla.Verdict
+		required   bool
+	}{
+		{"nothing", needcla.Details{}, 0, needcla.VerdictNotRequired, false},
+		{"readme only", needcla.Details{InREADME: true}, 0.4, needcla.VerdictUncertain, true},
+		{"action", needcla.Details{Action: true}, 0.95, needcla.VerdictRequired, true},
+		{"readme and contributing", needcla.Details{InREADME: true, InContributing: true}, 0.82, needcla.VerdictRequired, true},
+		{"explicitly not required", needcla.Details{InContributing: true, Results: map[string]bool{needcla.NoCLADetector: true}}, 0.14, needcla.VerdictNotRequired, false},
+		{"only negative", needcla.Details{Results: map[string]bool{needcla.NoCLADetector: true}}, 0, needcla.VerdictNotRequired, false},
+		{"custom", needcla.Details{Results: map[string]bool{"custom": true}}, 0.5, needcla.VerdictUncertain, true},
+		{"dco only", needcla.Details{Results: map[string]bool{needcla.DCODetector: true}}, 0, needcla.VerdictNotRequired, false},
+	}
+	for _, tt := range tests {
+		if c := tt.d.Confidence(); math.Abs(c-tt.confidence) > 1e-9 {
+			t.Errorf("%s: got confidence %v, wanted %v", tt.name, c, tt.confidence)
+		}
+		if v := tt.d.Verdict(); v != tt.verdict {
+			t.Errorf("%s: got verdict %v, wanted %v", tt.name, v, tt.verdict)
+		}
+		if r := tt.d.Required(); r != tt.required {
+			t.Errorf("%s: got required %v, wanted %v", tt.name, r, tt.required)
+		}
+	}
+}
+
+func TestScoringWeights(t *testing.T) {
+	sc := needcla.Scoring{
+		Weights:       map[string]float64{needcla.InREADMEDetector: 0.9, needcla.NoCLADetector: -0.5},
+		RequiredAt:    0.8,
+		NotRequiredAt: 0.1,
+	}
+	d := needcla.Details{InREADME: true, Known: true}
+	if c := sc.Confidence(d); math.Abs(c-0.9) > 1e-9 {
+		t.Errorf("expected unweighted detectors to use DefaultWeight 0, got confidence %v", c)
+	}
+	d.Results = map[string]bool{needcla.NoCLADetector: true}
+	if v := sc.Verdict(d); v != needcla.VerdictUncertain {
+		t.Errorf("expected a negative weight to make the verdict uncertain, got %v", v)
+	}
+}
+
+func TestScoringHeadingBoost(t *testing.T) {
+	d := needcla.Details{
+		InREADME: true,
+		Evidence: []needcla.Evidence{{Detector: needcla.InREADMEDetector, Heading: "Usage > Contributing"}},
+	}
+	if c := d.Confidence(); math.Abs(c-0.55) > 1e-9 {
+		t.Errorf("expected a mention under Contributing to be boosted, got confidence %v", c)
+	}
+	d.Evidence[0].Heading = "Usage"
+	if c := d.Confidence(); math.Abs(c-0.4) > 1e-9 {
+		t.Errorf("expected a mention under Usage not to be boosted, got confidence %v", c)
+	}
+}
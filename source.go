@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// Source provides the files of a repository at a single revision
+type Source interface {
+	// Find returns the entry at path, or nil if it doesn't exist
+	Find(ctx context.Context, path string) (*Entry, error)
+	// List returns the entries directly inside dir, or nil if it doesn't exist.
+	// The root of the repository is "".
+	List(ctx context.Context, dir string) ([]*Entry, error)
+	// ReadFile returns the contents of a file entry returned by Find or List
+	ReadFile(ctx context.Context, e *Entry) ([]byte, error)
+}
+
+// Entry is a file or directory in a Source
+type Entry struct {
+	// Path is relative to the root of the repository and separated by slashes
+	Path string
+	// Dir is true if the entry is a directory
+	Dir bool
+	// SHA is the git object ID of the entry, if the Source knows it
+	SHA string
+	// Repo is the owner/name of the repository the entry is from, if it isn't the one being checked,
+	// like an org's default community health files
+	Repo string
+}
+
+// revisioner is implemented by sources that know the commit they read from
+type revisioner interface {
+	revision() string
+}
+
+type fsSource struct {
+	fsys fs.FS
+}
+
+// NewFSSource returns a Source that reads a repository from fsys
+func NewFSSource(fsys fs.FS) Source {
+	return fsSource{fsys}
+}
+
+// NewDirSource returns a Source that reads a repository checked out at dir
+func NewDirSource(dir string) Source {
+	return NewFSSource(os.DirFS(dir))
+}
+
+func (f fsSource) Find(ctx context.Context, path string) (*Entry, error) {
+	info, err := fs.Stat(f.fsys, fsPath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{Path: path, Dir: info.IsDir()}, nil
+}
+
+func (f fsSource) List(ctx context.Context, dir string) ([]*Entry, error) {
+	des, err := fs.ReadDir(f.fsys, fsPath(dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, 0, len(des))
+	for _, de := range des {
+		entries = append(entries, &Entry{Path: joinPath(dir, de.Name()), Dir: de.IsDir()})
+	}
+	return entries, nil
+}
+
+func (f fsSource) ReadFile(ctx context.Context, e *Entry) ([]byte, error) {
+	return fs.ReadFile(f.fsys, fsPath(e.Path))
+}
+
+// listedSource is a Source that lists directories as detectors ask for them, reading files on demand.
+// list returns nil for a directory that doesn't exist.
+type listedSource struct {
+	list func(ctx context.Context, dir string) ([]*Entry, error)
+	read func(ctx context.Context, e *Entry) ([]byte, error)
+
+	mu   sync.Mutex
+	dirs map[string][]*Entry
+}
+
+func (l *listedSource) Find(ctx context.Context, path string) (*Entry, error) {
+	dir, _ := splitPath(path)
+	entries, err := l.List(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Path == path {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (l *listedSource) List(ctx context.Context, dir string) ([]*Entry, error) {
+	l.mu.Lock()
+	entries, ok := l.dirs[dir]
+	l.mu.Unlock()
+	if ok {
+		return entries, nil
+	}
+	entries, err := l.list(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	if l.dirs == nil {
+		l.dirs = make(map[string][]*Entry)
+	}
+	l.dirs[dir] = entries
+	l.mu.Unlock()
+	return entries, nil
+}
+
+func (l *listedSource) ReadFile(ctx context.Context, e *Entry) ([]byte, error) {
+	return l.read(ctx, e)
+}
+
+// fsPath converts a repository path to an io/fs path, where the root is "."
+func fsPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
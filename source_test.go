@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	needcla "github.com/progressive-insurance/need-cla"
+)
+
+func TestDetailSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md":       {Data: []byte("# Example\n")},
+		"CONTRIBUTING.md": {Data: []byte("Please sign our Contributor License Agreement.\n")},
+		".clabot":         {Data: []byte("{}")},
+		".github/workflows/cla.yml": {Data: []byte(
+			"steps:\n  - uses: cla-assistant/github-action@v2.1.3-beta\n",
+		)},
+	}
+
+	d, err := needcla.DetailSource(context.Background(), needcla.NewFSSource(fsys), "progressive-insurance", "need-cla")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := needcla.Details{
+		Known:          true,
+		BotFile:        true,
+		InContributing: true,
+		Action:         true,
+	}
+	if d.Known != want.Known || d.Tag != want.Tag || d.BotFile != want.BotFile ||
+		d.InContributing != want.InContributing || d.InREADME != want.InREADME || d.Action != want.Action {
+		t.Errorf("got %+v, wanted %+v", d, want)
+	}
+}
+
+func TestFSSource(t *testing.T) {
+	ctx := context.Background()
+	src := needcla.NewFSSource(fstest.MapFS{
+		"docs/CONTRIBUTING.md": {Data: []byte("contributing")},
+	})
+
+	e, err := src.Find(ctx, "docs")
+	if err != nil || e == nil || !e.Dir {
+		t.Errorf("expected docs to be a directory, got %+v, %v", e, err)
+	}
+	e, err = src.Find(ctx, "missing.md")
+	if err != nil || e != nil {
+		t.Errorf("expected nil for a missing file, got %+v, %v", e, err)
+	}
+
+	entries, err := src.List(ctx, "docs")
+	if err != nil || len(entries) != 1 || entries[0].Path != "docs/CONTRIBUTING.md" {
+		t.Fatalf("unexpected listing %+v, %v", entries, err)
+	}
+	content, err := src.ReadFile(ctx, entries[0])
+	if err != nil || string(content) != "contributing" {
+		t.Errorf("unexpected content %q, %v", content, err)
+	}
+}
+
+func TestDetailSourceNoCLA(t *testing.T) {
+	fsys := fstest.MapFS{
+		"CONTRIBUTING.md": {Data: []byte("We don't require a Contributor License Agreement, just open a PR.\n")},
+	}
+	d, err := needcla.DetailSource(context.Background(), needcla.NewFSSource(fsys), "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.InContributing || !d.Result(needcla.NoCLADetector) {
+		t.Errorf("expected only the statement that no CLA is needed, not a CLA mention, got %+v", d)
+	}
+	if d.Required() || d.Verdict() != needcla.VerdictNotRequired {
+		t.Errorf("expected no CLA to be required, got confidence %v", d.Confidence())
+	}
+}
+
+func TestDetailSourceDCO(t *testing.T) {
+	fsys := fstest.MapFS{
+		".github/dco.yml": {Data: []byte("require:\n  members: false\n")},
+		"CONTRIBUTING.md": {Data: []byte("Sign off every commit with `git commit -s` to add a Signed-off-by line.\n")},
+		".github/workflows/dco.yml": {Data: []byte(
+			"steps:\n  - name: DCO\n    uses: tisonkun/actions-dco@v1.1\n",
+		)},
+	}
+	d, err := needcla.DetailSource(context.Background(), needcla.NewFSSource(fsys), "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.Result(needcla.DCODetector) {
+		t.Errorf("expected a DCO to be detected, got %+v", d)
+	}
+	if d.Required() {
+		t.Errorf("expected a DCO not to count as a CLA, got confidence %v", d.Confidence())
+	}
+	var evidence int
+	for _, ev := range d.Evidence {
+		if ev.Detector == needcla.DCODetector {
+			evidence++
+		}
+	}
+	if evidence != 3 {
+		t.Errorf("expected evidence from the app config, workflow and CONTRIBUTING.md, got %+v", d.Evidence)
+	}
+}
+
+func TestDetailSourceEasyCLA(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md": {Data: []byte("# Example\n\n" +
+			"[![CLA assistant](https://img.shields.io/badge/CLA-EasyCLA-blue)](https://api.easycla.lfx.linuxfoundation.org/v2/repository-provider/github/sign/1/2/3)\n")},
+	}
+	d, err := needcla.DetailSource(context.Background(), needcla.NewFSSource(fsys), "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.Result(needcla.EasyCLADetector) || d.Provider != needcla.ProviderEasyCLA {
+		t.Errorf("expected EasyCLA to be detected as the provider, got %+v", d)
+	}
+	if d.Verdict() != needcla.VerdictRequired {
+		t.Errorf("expected EasyCLA to require a CLA, got confidence %v", d.Confidence())
+	}
+
+	fsys = fstest.MapFS{
+		".github/settings.yml": {Data: []byte("branches:\n  - name: main\n    protection:\n      required_status_checks:\n        contexts: [\"EasyCLA\"]\n")},
+	}
+	d, err = needcla.DetailSource(context.Background(), needcla.NewFSSource(fsys), "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.Result(needcla.EasyCLADetector) || len(d.Evidence) != 1 || d.Evidence[0].Path != ".github/settings.yml" || d.Evidence[0].Line != 5 {
+		t.Errorf("expected EasyCLA to be found in .github/settings.yml, got %+v", d)
+	}
+}
+
+func TestDetailSourceProvider(t *testing.T) {
+	fsys := fstest.MapFS{
+		".clabot": {Data: []byte("{}")},
+		"CONTRIBUTING.md": {Data: []byte("Please sign the CLA at https://cla-assistant.io/o/r " +
+			"or mail it to https://example.com/cla.\n")},
+	}
+	d, err := needcla.DetailSource(context.Background(), needcla.NewFSSource(fsys), "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Provider != needcla.ProviderCLABot {
+		t.Errorf("expected the .clabot file to name the provider, got %q", d.Provider)
+	}
+	want := []string{"https://cla-assistant.io/o/r", "https://example.com/cla"}
+	if strings.Join(d.SigningURLs, " ") != strings.Join(want, " ") {
+		t.Errorf("got signing URLs %v, wanted %v", d.SigningURLs, want)
+	}
+}
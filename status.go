@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// statusSample is how many recent pull requests have their head commit's statuses checked
+const statusSample = 5
+
+// DefaultStatusContexts are the commit status contexts and check run names CLA bots report,
+// used when Options.StatusContexts is nil. They're matched case-insensitively.
+var DefaultStatusContexts = []string{
+	"license/cla",
+	"cla/google",
+	"EasyCLA",
+	"CLA Assistant Lite",
+	"CLAssistant",
+	"verification/cla-signed",
+	"cla-bot",
+}
+
+// prStatuses are the statuses on a pull request's head commit
+type prStatuses struct {
+	pr       PullRequest
+	statuses []Status
+}
+
+// statusCache holds the result of sampling pull request statuses once
+type statusCache struct {
+	once sync.Once
+	prs  []prStatuses
+	err  error
+}
+
+// recentStatuses returns the statuses on the head commits of up to statusSample recent pull requests.
+// It's nil for snapshots whose forge doesn't report statuses.
+func (s *Snapshot) recentStatuses(ctx context.Context) ([]prStatuses, error) {
+	if _, ok := s.forge.(StatusLister); !ok {
+		return nil, nil
+	}
+	if s.statuses == nil {
+		return s.recentStatusesUncached(ctx)
+	}
+	s.statuses.once.Do(func() {
+		s.statuses.prs, s.statuses.err = s.recentStatusesUncached(ctx)
+	})
+	return s.statuses.prs, s.statuses.err
+}
+
+func (s *Snapshot) recentStatusesUncached(ctx context.Context) ([]prStatuses, error) {
+	lister := s.forge.(StatusLister)
+	prs, err := s.pullRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var sampled []prStatuses
+	for _, pr := range prs {
+		if pr.HeadSHA == "" {
+			continue
+		}
+		if len(sampled) == statusSample {
+			break
+		}
+		statuses, err := lister.Statuses(ctx, s.owner, s.repo, pr.HeadSHA)
+		if err != nil {
+			return nil, fmt.Errorf("error getting PR #%d statuses: %v", pr.Number, err)
+		}
+		sampled = append(sampled, prStatuses{pr: pr, statuses: statuses})
+	}
+	return sampled, nil
+}
+
+// hasCLAStatus looks for CLA bot statuses or check runs on the head commits of recent pull requests
+func (s *Snapshot) hasCLAStatus(ctx context.Context) (bool, error) {
+	contexts := s.statusContexts
+	if contexts == nil {
+		contexts = DefaultStatusContexts
+	}
+	sampled, err := s.recentStatuses(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, ps := range sampled {
+		for _, st := range ps.statuses {
+			if !isCLAStatus(st, contexts) {
+				continue
+			}
+			ev := Evidence{PullRequest: ps.pr.Number, SHA: ps.pr.HeadSHA, Status: st.Context, Snippet: st.Creator}
+			// bots like cla-assistant.io link their status to the signing page
+			if u, err := url.Parse(st.URL); err == nil && st.URL != "" {
+				if p := signingProvider(u, false); p != "" {
+					ev.URL, ev.Provider = st.URL, p
+				}
+			}
+			if isEasyCLAStatus(st) {
+				ev.Provider = ProviderEasyCLA
+			}
+			s.AddEvidence(ev)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isCLAStatus reports whether st's context is one of contexts
+func isCLAStatus(st Status, contexts []string) bool {
+	for _, c := range contexts {
+		if strings.EqualFold(strings.TrimSpace(st.Context), c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-github/v43/github"
+)
+
+// newStatusMux serves two pull requests, the older of which has a license/cla status from cla-assistant.io
+func newStatusMux(calls *int32) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []*github.PullRequest{
+			{Number: github.Int(2), Head: &github.PullRequestBranch{SHA: github.String("two")}},
+			{Number: github.Int(1), Head: &github.PullRequestBranch{SHA: github.String("one")}},
+		})
+	})
+	mux.HandleFunc("/repos/o/r/commits/two/statuses", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		writeJSON(w, []*github.RepoStatus{{Context: github.String("ci/cla-check"), State: github.String("success")}})
+	})
+	mux.HandleFunc("/repos/o/r/commits/one/statuses", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		writeJSON(w, []*github.RepoStatus{{
+			Context:   github.String("license/cla"),
+			State:     github.String("success"),
+			TargetURL: github.String("https://cla-assistant.io/o/r?pullRequest=1"),
+			Creator:   &github.User{Login: github.String("CLAassistant")},
+		}})
+	})
+	for _, sha := range []string{"one", "two"} {
+		mux.HandleFunc("/repos/o/r/commits/"+sha+"/check-runs", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, github.ListCheckRunsResults{})
+		})
+	}
+	return mux
+}
+
+func TestHasCLAStatus(t *testing.T) {
+	var calls int32
+	s := &Snapshot{
+		owner:    "o",
+		repo:     "r",
+		forge:    GitHubForge(newTestClient(t, newStatusMux(&calls))),
+		src:      NewFSSource(fstest.MapFS{}),
+		detector: StatusDetector,
+		evidence: new(evidenceLog),
+	}
+	found, err := s.hasCLAStatus(context.Background())
+	if err != nil || !found {
+		t.Fatalf("expected the license/cla status to be found, got %v, %v", found, err)
+	}
+	want := Evidence{
+		Detector:    StatusDetector,
+		PullRequest: 1,
+		SHA:         "one",
+		Status:      "license/cla",
+		Snippet:     "CLAassistant",
+		URL:         "https://cla-assistant.io/o/r?pullRequest=1",
+		Provider:    ProviderCLAAssistant,
+	}
+	if got := s.evidence.list(); len(got) != 1 || got[0] != want {
+		t.Errorf("got evidence %+v, wanted %+v", got, want)
+	}
+
+	s.evidence, s.statusContexts = new(evidenceLog), []string{"CI/CLA-Check"}
+	found, err = s.hasCLAStatus(context.Background())
+	if err != nil || !found {
+		t.Fatalf("expected the configured status context to be found, got %v, %v", found, err)
+	}
+	if got := s.evidence.list(); len(got) != 1 || got[0].PullRequest != 2 {
+		t.Errorf("expected evidence from PR #2, got %+v", got)
+	}
+}
+
+func TestRecentStatusesCached(t *testing.T) {
+	var calls int32
+	s := &Snapshot{
+		owner: "o",
+		repo:  "r",
+		forge: GitHubForge(newTestClient(t, newStatusMux(&calls))),
+		src:   NewFSSource(fstest.MapFS{}),
+	}
+	var detectors []Detector
+	for _, det := range Detectors() {
+		if det.Name() == StatusDetector || det.Name() == EasyCLADetector {
+			detectors = append(detectors, det)
+		}
+	}
+	d, err := s.detail(context.Background(), detectors, new(Errors))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.Result(StatusDetector) || d.Result(EasyCLADetector) {
+		t.Errorf("expected only a CLA status, got %+v", d)
+	}
+	if calls != 2 {
+		t.Errorf("expected each sampled commit's statuses to be listed once, got %d requests", calls)
+	}
+}
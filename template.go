@@ -0,0 +1,149 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Names of GitHub's template files and directories, which are resolved like community documents
+const (
+	prTemplate    = "PULL_REQUEST_TEMPLATE"
+	issueTemplate = "ISSUE_TEMPLATE"
+)
+
+// gitlabTemplateDirs are where GitLab keeps merge request and issue templates
+var gitlabTemplateDirs = []string{".gitlab/merge_request_templates", ".gitlab/issue_templates"}
+
+// claCheckbox finds CLA checkboxes, like "- [ ] I have signed the CLA"
+var claCheckbox = mustMatcher("cla-checkbox", `(?im)^[[:space:]]*[-*][[:space:]]*\[[ xX]?\][^\n]*(?:\bCLAs?\b|(?i:contributor\s+license\s+agreement))`)
+
+// findTemplates returns the PR and issue templates in src:
+// PULL_REQUEST_TEMPLATE and ISSUE_TEMPLATE files and directories in the root, .github or docs, and GitLab's templates
+func findTemplates(ctx context.Context, src Source) ([]*Entry, error) {
+	var templates []*Entry
+	for _, dir := range docDirs {
+		entries, err := src.List(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			_, base := splitPath(e.Path)
+			switch {
+			case !e.Dir && (isDoc(e.Path, prTemplate) || isDoc(e.Path, issueTemplate)):
+				templates = append(templates, e)
+			case e.Dir && (strings.EqualFold(base, prTemplate) || strings.EqualFold(base, issueTemplate)):
+				files, err := templateFiles(ctx, src, e.Path)
+				if err != nil {
+					return nil, err
+				}
+				templates = append(templates, files...)
+			}
+		}
+	}
+	for _, dir := range gitlabTemplateDirs {
+		files, err := templateFiles(ctx, src, dir)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, files...)
+	}
+	return templates, nil
+}
+
+// templateFiles returns the templates in dir, skipping issue template config.yml
+func templateFiles(ctx context.Context, src Source, dir string) ([]*Entry, error) {
+	entries, err := src.List(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []*Entry
+	for _, e := range entries {
+		_, base := splitPath(e.Path)
+		base = strings.ToLower(base)
+		if e.Dir || base == "config.yml" || base == "config.yaml" {
+			continue
+		}
+		if isMarkdown(base) || strings.HasSuffix(base, ".yml") || strings.HasSuffix(base, ".yaml") {
+			files = append(files, e)
+		}
+	}
+	return files, nil
+}
+
+// referencesCLAInTemplates looks for CLA checkboxes and references in PR and issue templates,
+// falling back to the org's defaults like GitHub does when the repository has none
+func (s *Snapshot) referencesCLAInTemplates(ctx context.Context) (bool, error) {
+	templates, err := findTemplates(ctx, s.src)
+	if err == ErrTruncatedTree {
+		return false, fmt.Errorf("tree was truncated and templates were possibly missed")
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(templates) == 0 {
+		org, err := s.orgDefaults(ctx)
+		if err != nil {
+			return false, err
+		}
+		if org != nil {
+			if templates, err = findTemplates(ctx, org); err != nil {
+				return false, fmt.Errorf("failed to check %s/%s: %v", s.owner, orgDefaultsRepo, err)
+			}
+			for i, e := range templates {
+				oe := *e
+				oe.Repo = s.owner + "/" + orgDefaultsRepo
+				templates[i] = &oe
+			}
+		}
+	}
+
+	errs := make(map[string]error)
+	for _, e := range templates {
+		content, err := s.readEntry(ctx, e)
+		if err != nil {
+			errs[e.Path] = err
+			continue
+		}
+		if ev := s.config.templateMatchers().matchFile(e.Path, content); ev != nil {
+			ev.at(e)
+			s.AddEvidence(*ev)
+			return true, nil
+		}
+	}
+
+	if len(errs) != 0 {
+		var lines []string
+		for path, err := range errs {
+			lines = append(lines, fmt.Sprintf("* %s: %v", path, err))
+		}
+		return false, fmt.Errorf("%d error(s) checking templates:\n\t%s", len(errs), strings.Join(lines, "\n\t"))
+	}
+	return false, nil
+}
+
+// templatesCost is the worst-case cost of s.referencesCLAInTemplates
+func (s *Snapshot) templatesCost() Cost {
+	c := Cost{}
+	for _, dir := range docDirs {
+		c = c.add(s.listCost(dir))
+	}
+	for _, dir := range []string{".github/" + prTemplate, ".github/" + issueTemplate} {
+		c = c.add(s.filesCost(dir))
+	}
+	for _, dir := range gitlabTemplateDirs {
+		c = c.add(s.filesCost(dir))
+	}
+	if _, ok := s.src.(sourceCoster); ok {
+		// a single PR template and issue template; the org defaults are shared with CONTRIBUTING
+		c = c.add(Cost{Core: 2})
+	}
+	return c
+}
@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2021-2022 Progressive Casualty Insurance Company. All rights reserved.
+
+Use of this source code is governed by an MIT license that can be found in
+the LICENSE file at https://github.com/Progressive-Insurance/need-cla/blob/main/LICENSE.md
+*/
+
+package needcla
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFindTemplates(t *testing.T) {
+	fsys := fstest.MapFS{
+		".github/pull_request_template.md":           {Data: []byte("")},
+		".github/ISSUE_TEMPLATE/bug_report.md":       {Data: []byte("")},
+		".github/ISSUE_TEMPLATE/feature.yml":         {Data: []byte("")},
+		".github/ISSUE_TEMPLATE/config.yml":          {Data: []byte("")},
+		"docs/PULL_REQUEST_TEMPLATE/release.md":      {Data: []byte("")},
+		".gitlab/merge_request_templates/Default.md": {Data: []byte("")},
+		".github/CONTRIBUTING.md":                    {Data: []byte("")},
+		"templates/ISSUE_TEMPLATE.md":                {Data: []byte("")},
+	}
+	templates, err := findTemplates(context.Background(), NewFSSource(fsys))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var paths []string
+	for _, e := range templates {
+		paths = append(paths, e.Path)
+	}
+	sort.Strings(paths)
+	want := []string{
+		".github/ISSUE_TEMPLATE/bug_report.md",
+		".github/ISSUE_TEMPLATE/feature.yml",
+		".github/pull_request_template.md",
+		".gitlab/merge_request_templates/Default.md",
+		"docs/PULL_REQUEST_TEMPLATE/release.md",
+	}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("got templates %v, wanted %v", paths, want)
+	}
+}
+
+func TestReferencesCLAInTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		found    bool
+		line     int
+	}{
+		{"checkbox", "## Checklist\n\n- [ ] Tests pass\n- [ ] I have signed the CLA\n", true, 4},
+		{"checked", "* [x] I agree to the Contributor License Agreement\n", true, 1},
+		{"prose", "Thanks! Make sure you've signed the Contributor License Agreement.\n", true, 1},
+		{"unrelated", "- [ ] I have read the CONTRIBUTING guide\n- [ ] Clarified the docs\n", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Snapshot{
+				src: NewFSSource(fstest.MapFS{
+					".github/PULL_REQUEST_TEMPLATE.md": {Data: []byte(tt.template)},
+				}),
+				detector: TemplateDetector,
+				evidence: new(evidenceLog),
+			}
+			found, err := s.referencesCLAInTemplates(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if found != tt.found {
+				t.Fatalf("got %v, wanted %v", found, tt.found)
+			}
+			if ev := s.evidence.list(); found && (len(ev) != 1 || ev[0].Path != ".github/PULL_REQUEST_TEMPLATE.md" || ev[0].Line != tt.line) {
+				t.Errorf("expected evidence on line %d of the template, got %+v", tt.line, ev)
+			}
+		})
+	}
+}